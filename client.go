@@ -1,6 +1,7 @@
 package fornitego
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,11 +9,24 @@ import (
 	"io/ioutil"
 	"net/http"
 	"runtime"
+	"time"
 )
 
 // Client is our HTTP client for this package to interface with Epic's API.
 type Client struct {
-	client *http.Client
+	client    *http.Client
+	endpoints Endpoints
+	limiter   *rateLimiter
+	retry     RetryPolicy
+	logger    Logger
+	metrics   Metrics
+	tracer    Tracer
+	userAgent string
+	headers   http.Header
+
+	middleware []Middleware
+	before     []func(endpoint string, req *http.Request)
+	after      []func(endpoint string, req *http.Request, resp *http.Response, err error)
 }
 
 // Version is the package version.
@@ -24,26 +38,64 @@ var userAgent = fmt.Sprintf(
 	Version, runtime.Version(), runtime.GOOS, runtime.GOARCH,
 )
 
+// defaultTimeout is the time limit for requests made by a Client, unless configured otherwise.
+const defaultTimeout = 30 * time.Second
+
 func newClient() *Client {
-	// Return default HTTP client for now. @todo replace with defined client
-	return &Client{client: &http.Client{}}
+	return &Client{
+		client:    &http.Client{Timeout: defaultTimeout},
+		endpoints: DefaultEndpoints,
+		limiter:   newRateLimiter(),
+		retry:     DefaultRetryPolicy,
+		logger:    nopLogger{},
+		metrics:   nopMetrics{},
+		tracer:    nopTracer{},
+		userAgent: userAgent,
+		headers:   http.Header{},
+	}
 }
 
 // NewRequest prepares a new HTTP request and sets the necessary headers.
 func (c *Client) NewRequest(method, url string, body io.Reader) (*http.Request, error) {
-	// Prepare new request.
-	req, err := http.NewRequest(method, url, body)
+	return c.newRequest(context.Background(), endpoint{}, method, url, body)
+}
+
+// NewRequestWithContext is like NewRequest, but binds the request to the context provided. Do abandons the request,
+// including any retries or rate limit waits, once the context is done.
+func (c *Client) NewRequestWithContext(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	return c.newRequest(ctx, endpoint{}, method, url, body)
+}
+
+// endpointKey is the context key under which the endpoint a request is made to is held.
+type endpointKey struct{}
+
+// endpointOf returns the endpoint a request prepared by newRequest is made to.
+func endpointOf(req *http.Request) endpoint {
+	ep, _ := req.Context().Value(endpointKey{}).(endpoint)
+	return ep
+}
+
+// newRequest prepares a new HTTP request to the given endpoint, bound to the given context, and sets the necessary
+// headers.
+func (c *Client) newRequest(ctx context.Context, ep endpoint, method, url string, body io.Reader) (*http.Request, error) {
+	// Prepare new request, noting the endpoint it's made to.
+	req, err := http.NewRequestWithContext(context.WithValue(ctx, endpointKey{}, ep), method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
+	// Set default headers configured for the client.
+	for k, v := range c.headers {
+		req.Header[k] = append([]string(nil), v...)
+	}
+
 	// If we're sending data, set appropriate content type.
 	if body != nil {
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	}
 
 	// Set user agent.
-	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("User-Agent", c.userAgent)
 
 	return req, nil
 }
@@ -55,18 +107,53 @@ var (
 )
 
 // Do processes a prepared HTTP request with the client provided. An interface is passed in to decode the response into.
+// Requests which fail transiently are retried according to the client's RetryPolicy.
 func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
-	// Process request using session's client. Collect response.
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
+	ep := endpointOf(req)
+	retry := c.retry.allows(req, ep)
+
+	// Trace the request as a child of any span in its context, propagating the trace context to Epic on a clone of the
+	// request, so as to leave the caller's headers untouched.
+	ctx, span := c.tracer.Start(req.Context(), "epic."+ep.name)
+	defer span.End()
+	req = req.Clone(ctx)
+	c.tracer.Inject(ctx, req.Header)
+	span.SetAttribute("endpoint", ep.name)
+	span.SetAttribute("http.method", req.Method)
+
+	var resp *http.Response
+	var err error
+	for attempt := 1; ; attempt++ {
+		span.SetAttribute("retry_count", attempt-1)
+		resp, err = c.attempt(req, ep)
+		if err == nil || !retry || !retryable(err) || attempt >= c.retry.MaxAttempts {
+			break
+		}
 
-	// Check response status codes to determine success/failure.
-	err = checkStatus(resp)
+		// Wait before retrying, giving up should the context be done first.
+		delay := c.retry.delay(attempt)
+		c.logger.Warn("retrying request", "endpoint", ep.name, "attempt", attempt, "delay", delay, "error", err)
+		c.metrics.IncRetry(ep.name)
+		if !sleep(req.Context(), delay) {
+			break
+		}
+
+		// Rewind the request body for the next attempt.
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
 	if err != nil {
+		var ae *APIError
+		if errors.As(err, &ae) {
+			span.SetAttribute("http.status_code", ae.StatusCode)
+		}
+		span.RecordError(redactError(err))
 		return nil, err
 	}
+	span.SetAttribute("http.status_code", resp.StatusCode)
 
 	// If an interface was provided, decode response body into it.
 	if v != nil {
@@ -79,8 +166,50 @@ func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
 	return resp, nil
 }
 
-// checkStatus checks the HTTP response status code for unsuccessful requests.
-// @todo decode error into Epic Error-JSON object to determine better errors.go?
+// attempt makes a single attempt at processing a request to the endpoint provided, subject to its rate limit.
+func (c *Client) attempt(req *http.Request, ep endpoint) (*http.Response, error) {
+	// Wait until the rate limit of the service requested permits the request.
+	if ep.service != "" {
+		waited, err := c.limiter.wait(req.Context(), ep.service)
+		if err != nil {
+			return nil, err
+		}
+		if waited > 0 {
+			c.metrics.ObserveRateLimitWait(ep.service, waited)
+			c.logger.Debug("request rate limited", "endpoint", ep.name, "service", ep.service, "waited", waited)
+		}
+	}
+
+	// Process request using session's client. Collect response.
+	start := time.Now()
+	resp, err := c.send(req)
+	if err != nil {
+		c.metrics.ObserveRequest(ep.name, 0, time.Since(start))
+		c.logger.Warn("request failed", "endpoint", ep.name, "method", req.Method, "latency", time.Since(start),
+			"error", redactError(err))
+		return nil, err
+	}
+	c.metrics.ObserveRequest(ep.name, resp.StatusCode, time.Since(start))
+	c.logger.Debug("request completed", "endpoint", ep.name, "method", req.Method, "status", resp.StatusCode,
+		"latency", time.Since(start))
+
+	// Hold back further requests to the service for as long as Epic asks, should we be rate limited.
+	if resp.StatusCode == http.StatusTooManyRequests && ep.service != "" {
+		if d := retryAfter(resp, time.Now()); d > 0 {
+			c.limiter.pause(ep.service, time.Now().Add(d))
+		}
+	}
+
+	// Check response status codes to determine success/failure.
+	if err := checkStatus(resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// checkStatus checks the HTTP response status code for unsuccessful requests, decoding the error returned by Epic into
+// an *APIError.
 func checkStatus(resp *http.Response) error {
 	switch resp.StatusCode {
 	case http.StatusOK, http.StatusNoContent:
@@ -92,6 +221,6 @@ func checkStatus(resp *http.Response) error {
 		}
 		defer resp.Body.Close()
 
-		return errors.New(fmt.Sprintf("unsuccessful response returned: %v %v", resp.StatusCode, string(b)))
+		return newAPIError(resp.StatusCode, b)
 	}
 }
@@ -0,0 +1,101 @@
+package fornitego
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// DeviceAuth is a credential issued by Epic for a specific account which may be used to authenticate in place of a
+// password. It should be stored as securely as a password would be, as it grants the same access.
+type DeviceAuth struct {
+	DeviceID  string `json:"deviceId"`
+	AccountID string `json:"accountId"`
+	Secret    string `json:"secret"`
+}
+
+// CreateDeviceAuth requests a new device auth credential for the account the session is authenticated as. The returned
+// credential can be persisted with SaveDeviceAuth and later used with NewSessionFromDeviceAuth.
+func (s *Session) CreateDeviceAuth(ctx context.Context) (_ *DeviceAuth, err error) {
+	ctx, span := s.startSpan(ctx, "CreateDeviceAuth")
+	defer endSpan(span, &err)
+
+	if err := s.acquire(); err != nil {
+		return nil, err
+	}
+	defer s.release()
+
+	u := s.client.endpoints.Account + fmt.Sprintf(deviceAuthPath, s.Token().AccountID)
+	req, err := s.authorizedRequest(ctx, deviceAuthEndpoint, http.MethodPost, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	da := &DeviceAuth{}
+	if err := s.do(req, da); err != nil {
+		return nil, err
+	}
+
+	return da, nil
+}
+
+// DeviceAuthTokenSource returns a TokenSource which authenticates using a device auth credential and the game token.
+func DeviceAuthTokenSource(da DeviceAuth, gameToken string) TokenSource {
+	return &deviceAuthSource{da: da, gameToken: gameToken}
+}
+
+type deviceAuthSource struct {
+	da        DeviceAuth
+	gameToken string
+}
+
+func (d *deviceAuthSource) Token(ctx context.Context, c *Client) (*Token, error) {
+	data := url.Values{}
+	data.Add("grant_type", "device_auth")
+	data.Add("account_id", d.da.AccountID)
+	data.Add("device_id", d.da.DeviceID)
+	data.Add("secret", d.da.Secret)
+	data.Add("includePerms", "true")
+	data.Add("token_type", "eg1")
+
+	tr, err := requestToken(ctx, c, d.gameToken, data)
+	if err != nil {
+		return nil, &AuthError{Step: AuthStepDeviceAuth, Err: err}
+	}
+
+	return tr.token(), nil
+}
+
+// NewSessionFromDeviceAuth opens a new connection to Epic, authenticating with a previously created device auth
+// credential rather than a password.
+func NewSessionFromDeviceAuth(ctx context.Context, da DeviceAuth, gameToken string, opts ...Option) (*Session, error) {
+	return NewSessionFromSource(ctx, DeviceAuthTokenSource(da, gameToken), gameToken, opts...)
+}
+
+// SaveDeviceAuth writes a device auth credential to the file at path as JSON, readable only by the current user.
+func SaveDeviceAuth(path string, da *DeviceAuth) error {
+	b, err := json.Marshal(da)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(path, b)
+}
+
+// LoadDeviceAuth reads a device auth credential previously written by SaveDeviceAuth.
+func LoadDeviceAuth(path string) (*DeviceAuth, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	da := &DeviceAuth{}
+	if err := json.Unmarshal(b, da); err != nil {
+		return nil, err
+	}
+
+	return da, nil
+}
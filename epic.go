@@ -2,25 +2,67 @@ package fornitego
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// Epic API endpoints
+// Endpoints holds the base URLs of the Epic services this package interfaces with. Allows pointing a Session at
+// different hosts, such as another production shard or a local server for testing.
+type Endpoints struct {
+	Account     string
+	Persona     string
+	Lightswitch string
+	Fortnite    string
+}
+
+// DefaultEndpoints are the base URLs of Epic's production services.
+var DefaultEndpoints = Endpoints{
+	Account:     "https://account-public-service-prod03.ol.epicgames.com",
+	Persona:     "https://persona-public-service-prod06.ol.epicgames.com",
+	Lightswitch: "https://lightswitch-public-service-prod06.ol.epicgames.com",
+	Fortnite:    "https://fortnite-public-service-prod11.ol.epicgames.com",
+}
+
+// Epic API endpoint paths, relative to the base URL of their service.
 const (
-	oauthTokenURL    = "https://account-public-service-prod03.ol.epicgames.com/account/api/oauth/token"
-	oauthExchangeURL = "https://account-public-service-prod03.ol.epicgames.com/account/api/oauth/exchange"
-	accountLookupURL = "https://persona-public-service-prod06.ol.epicgames.com/persona/api/public/account"
-	accountInfoURL   = "https://account-public-service-prod03.ol.epicgames.com/account/api/public/account"
-	killSessionURL   = "https://account-public-service-prod03.ol.epicgames.com/account/api/oauth/sessions/kill"
-
-	serverStatusURL    = "https://lightswitch-public-service-prod06.ol.epicgames.com/lightswitch/api/service/bulk/status?serviceId=Fortnite"
-	accountStatsURL    = "https://fortnite-public-service-prod11.ol.epicgames.com/fortnite/api/stats/accountId"
-	winsLeaderboardURL = "https://fortnite-public-service-prod11.ol.epicgames.com/fortnite/api/leaderboards/type/global/stat/br_placetop1_%v_m0%v/window/weekly"
+	oauthTokenPath    = "/account/api/oauth/token"
+	oauthExchangePath = "/account/api/oauth/exchange"
+	accountLookupPath = "/persona/api/public/account"
+	accountInfoPath   = "/account/api/public/account"
+	killSessionPath   = "/account/api/oauth/sessions/kill"
+	deviceAuthPath    = "/account/api/public/account/%v/deviceAuth"
+
+	serverStatusPath    = "/lightswitch/api/service/bulk/status?serviceId=Fortnite"
+	accountStatsPath    = "/fortnite/api/stats/accountId"
+	winsLeaderboardPath = "/fortnite/api/leaderboards/type/global/stat/br_placetop1_%v_m0%v/window/weekly"
+)
+
+// endpoint identifies an Epic API endpoint requested by this package, and the service it belongs to. Requests to
+// idempotent endpoints may be retried regardless of their method.
+type endpoint struct {
+	name       string
+	service    Service
+	idempotent bool
+}
+
+// Epic API endpoints, named for identification in rate limiting.
+var (
+	oauthTokenEndpoint      = endpoint{"oauth_token", ServiceAccount, false}
+	oauthExchangeEndpoint   = endpoint{"oauth_exchange", ServiceAccount, false}
+	accountLookupEndpoint   = endpoint{"account_lookup", ServicePersona, false}
+	accountInfoEndpoint     = endpoint{"account_info", ServiceAccount, false}
+	killSessionEndpoint     = endpoint{"kill_session", ServiceAccount, false}
+	deviceAuthEndpoint      = endpoint{"device_auth", ServiceAccount, false}
+	serverStatusEndpoint    = endpoint{"server_status", ServiceLightswitch, false}
+	accountStatsEndpoint    = endpoint{"account_stats", ServiceStats, false}
+	winsLeaderboardEndpoint = endpoint{"wins_leaderboard", ServiceLeaderboard, true} // POST, but only reads.
 )
 
 // Platform types
@@ -55,20 +97,51 @@ type lookupResponse struct {
 	DisplayName string `json:"displayName"`
 }
 
-// statsResponse defines the response collected by a request to the battle royal stats endpoint.
-type statsResponse []statsRecord
+// StatsResponse defines the response collected by a request to the battle royal stats endpoint. It holds every record
+// Epic returns for a player, across all platforms and playlists.
+type StatsResponse []StatRecord
 
-// statsRecord defines a single entry in a statsResponse.
-type statsRecord struct {
+// StatRecord defines a single entry in a StatsResponse.
+type StatRecord struct {
 	Name      string `json:"name"`
 	Value     int    `json:"value"`
 	Window    string `json:"window"`
 	OwnerType int    `json:"ownerType"`
+
+	// Key is the parsed form of Name, left empty should Name not be in the form Epic records statistics under.
+	Key StatKey `json:"-"`
+}
+
+// UnmarshalJSON decodes a record returned by Epic, parsing its name into Key.
+func (r *StatRecord) UnmarshalJSON(b []byte) error {
+	type record StatRecord
+	if err := json.Unmarshal(b, (*record)(r)); err != nil {
+		return err
+	}
+
+	r.Key, _ = ParseStatKey(r.Name)
+	return nil
+}
+
+// Window is the period of time statistics are recorded over. Any window Epic supports may be given, beyond those
+// defined here.
+type Window string
+
+// Statistics windows
+const (
+	WindowAllTime Window = "alltime"
+	WindowWeekly  Window = "weekly"
+)
+
+// SeasonWindow returns the Window covering the statistics of a specific season.
+func SeasonWindow(season int) Window {
+	return Window(fmt.Sprintf("season%v", season))
 }
 
 // Player is the hierarchical struct used to contain information regarding a player's account info and stats.
 type Player struct {
 	AccountInfo AccountInfo
+	Window      Window
 	Stats       Stats
 }
 
@@ -81,13 +154,21 @@ type AccountInfo struct {
 
 // Stats is the structure which holds the player's stats for the 3 different game modes offered in Battle Royal.
 type Stats struct {
-	Solo  statDetails
-	Duo   statDetails
-	Squad statDetails
+	Solo  StatDetails
+	Duo   StatDetails
+	Squad StatDetails
+
+	// ByPlaylist holds the stats of every playlist played on the platform, such as limited time modes, playground and
+	// creative, keyed by the playlist as Epic names it (e.g. "p2" for solo).
+	ByPlaylist map[string]StatDetails `json:",omitempty"`
+
+	// Unmapped holds the value of every record for the platform which is not held by StatDetails, keyed by name.
+	Unmapped map[string]int `json:",omitempty"`
 }
 
-// statDetails is the specific statistics for any given group mode.
-type statDetails struct {
+// StatDetails is the specific statistics for any given group mode. The ratio fields are formatted to 2 decimal places;
+// use Ratios for their values at full precision.
+type StatDetails struct {
 	Wins           int
 	Top3           int `json:",omitempty"` // Squad-only
 	Top5           int `json:",omitempty"` // Duo-only
@@ -103,6 +184,7 @@ type statDetails struct {
 	KillsPerMatch  string
 	KillsPerMinute string
 	Score          int
+	LastModified   *time.Time `json:",omitempty"` // The time the statistics were last updated by Epic, if known.
 }
 
 // GlobalWinsLeaderboard contains an array of the top X players by wins on a specific platform and party mode.
@@ -118,29 +200,53 @@ type leaderboardEntry struct {
 // QueryPlayer looks up a player by their username and platform, and returns information about that player, namely, the
 // statistics for the 3 different party modes.
 func (s *Session) QueryPlayer(name string, accountId string, platform string) (*Player, error) {
+	return s.QueryPlayerContext(context.Background(), name, accountId, platform)
+}
+
+// QueryPlayerContext is like QueryPlayer, but bound to the context provided.
+func (s *Session) QueryPlayerContext(ctx context.Context, name, accountId, platform string) (*Player, error) {
+	return s.QueryPlayerWindow(ctx, name, accountId, platform, WindowAllTime)
+}
+
+// QueryPlayerWindow is like QueryPlayerContext, but returns the statistics recorded over the window provided rather
+// than the player's lifetime.
+func (s *Session) QueryPlayerWindow(ctx context.Context, name, accountId, platform string, window Window) (
+	_ *Player, err error) {
+	if window == "" {
+		window = WindowAllTime
+	}
+
+	ctx, span := s.startSpan(ctx, "QueryPlayer")
+	defer endSpan(span, &err)
+	span.SetAttribute("platform", platform)
+	span.SetAttribute("window", string(window))
+
+	if err := s.acquire(); err != nil {
+		return nil, err
+	}
+	defer s.release()
+
 	if name == "" && accountId == "" {
 		return nil, errors.New("no player name or id provided")
 	}
-	switch platform {
-	case PC, Xbox, PS4:
-	default:
-		return nil, errors.New("invalid platform specified")
+	if !validPlatform(platform) {
+		return nil, ErrInvalidPlatform
 	}
 
 	if name != "" && accountId == "" {
-		userInfo, err := s.findUserInfo(name)
+		userInfo, err := s.findUserInfo(ctx, name)
 		if err != nil {
 			return nil, err
 		}
 		accountId = userInfo.ID
 	}
 
-	sr, err := s.QueryPlayerById(accountId)
+	sr, err := s.queryPlayerByIdWindow(ctx, accountId, window)
 	if err != nil {
 		return nil, err
 	}
 
-	acctInfoMap, err := s.getAccountNames([]string{accountId})
+	acctInfoMap, err := s.getAccountNames(ctx, []string{accountId})
 	if err != nil {
 		return nil, err
 	}
@@ -152,58 +258,102 @@ func (s *Session) QueryPlayer(name string, accountId string, platform string) (*
 			Username:  acctInfoMap[cleanAcctID],
 			Platform:  platform,
 		},
-		Stats: s.mapStats(sr, platform),
+		Window: window,
+		Stats:  s.mapStats(sr, platform),
 	}, nil
 }
 
-func (s *Session) QueryPlayerById(accountId string) (*statsResponse, error) {
-	u := fmt.Sprintf("%v/%v/%v/%v/%v", accountStatsURL, accountId, "bulk", "window", "alltime")
-	req, err := s.client.NewRequest(http.MethodGet, u, nil)
-	if err != nil {
+// QueryPlayerById retrieves the lifetime statistics recorded for a player by their account ID, as every raw record Epic
+// holds across all platforms and playlists. Returns ErrPlayerNotFound if no such account exists, or ErrNoStats if the
+// player has none.
+func (s *Session) QueryPlayerById(accountId string) (*StatsResponse, error) {
+	return s.QueryPlayerByIdContext(context.Background(), accountId)
+}
+
+// QueryPlayerByIdContext is like QueryPlayerById, but bound to the context provided.
+func (s *Session) QueryPlayerByIdContext(ctx context.Context, accountId string) (*StatsResponse, error) {
+	return s.QueryPlayerByIdWindow(ctx, accountId, WindowAllTime)
+}
+
+// QueryPlayerByIdWindow is like QueryPlayerByIdContext, but retrieves the statistics recorded over the window provided
+// rather than the player's lifetime.
+func (s *Session) QueryPlayerByIdWindow(ctx context.Context, accountId string, window Window) (_ *StatsResponse,
+	err error) {
+	if window == "" {
+		window = WindowAllTime
+	}
+
+	ctx, span := s.startSpan(ctx, "QueryPlayerById")
+	defer endSpan(span, &err)
+	span.SetAttribute("account_id", accountId)
+	span.SetAttribute("window", string(window))
+
+	if err := s.acquire(); err != nil {
 		return nil, err
 	}
+	defer s.release()
 
-	// Set authorization to use access token.
-	req.Header.Set("Authorization", fmt.Sprintf("%v %v", AuthBearer, s.AccessToken))
+	return s.queryPlayerByIdWindow(ctx, accountId, window)
+}
 
-	sr := &statsResponse{}
-	resp, err := s.client.Do(req, sr)
+// queryPlayerByIdWindow retrieves the statistics recorded for a player over the window provided, on behalf of a caller
+// which has already acquired the session.
+func (s *Session) queryPlayerByIdWindow(ctx context.Context, accountId string, window Window) (*StatsResponse, error) {
+	u := fmt.Sprintf("%v%v/%v/%v/%v/%v", s.client.endpoints.Fortnite, accountStatsPath, accountId, "bulk", "window",
+		url.PathEscape(string(window)))
+	req, err := s.authorizedRequest(ctx, accountStatsEndpoint, http.MethodGet, u, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+
+	sr := &StatsResponse{}
+	if err := s.do(req, sr); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("%w: %w", ErrPlayerNotFound, err)
+		}
+		return nil, err
+	}
 
 	if len(*sr) == 0 {
-		return nil, errors.New("no statistics found for player " + accountId)
+		return nil, fmt.Errorf("%w for player %v", ErrNoStats, accountId)
 	}
 
 	return sr, nil
 }
 
 // findUserInfo requests additional account information by a username.
-func (s *Session) findUserInfo(username string) (*lookupResponse, error) {
-	req, err := s.client.NewRequest(http.MethodGet, accountLookupURL+"/lookup?q="+url.QueryEscape(username), nil)
+func (s *Session) findUserInfo(ctx context.Context, username string) (*lookupResponse, error) {
+	u := s.client.endpoints.Persona + accountLookupPath + "/lookup?q=" + url.QueryEscape(username)
+	req, err := s.authorizedRequest(ctx, accountLookupEndpoint, http.MethodGet, u, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	// Set authorization to use access token.
-	req.Header.Set("Authorization", fmt.Sprintf("%v %v", AuthBearer, s.AccessToken))
-
 	ret := &lookupResponse{}
-	resp, err := s.client.Do(req, ret)
-	if err != nil {
+	if err := s.do(req, ret); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("%w: %w", ErrPlayerNotFound, err)
+		}
 		return nil, err
 	}
-	defer resp.Body.Close()
 
 	if ret.ID == "" {
-		return nil, errors.New("player not found")
+		return nil, ErrPlayerNotFound
 	}
 
 	return ret, nil
 }
 
+// validPlatform reports whether the platform provided is one of the supported platform types.
+func validPlatform(platform string) bool {
+	switch platform {
+	case PC, Xbox, PS4:
+		return true
+	default:
+		return false
+	}
+}
+
 // Name identifiers for group type. Used in parsing URLs and responses.
 const (
 	Solo  = "_p2"
@@ -211,78 +361,89 @@ const (
 	Squad = "_p9"
 )
 
-// getStatType is a simple helper function to return the party type if present in a given string.
-func getStatType(seed string) string {
-	switch {
-	case strings.Contains(seed, Solo):
-		return Solo
-	case strings.Contains(seed, Duo):
-		return Duo
-	default: // _p9
-		return Squad
+// mapStats takes a StatsResponse object and converts it into a Stats object. It parses the JSON returned from Epic
+// regarding a player's stats, and maps it accordingly based on playlist, as well as calculates several useful ratios.
+// Records for the platform which cannot be mapped are retained in Stats.Unmapped, while records for other platforms are
+// ignored.
+func (s *Session) mapStats(records *StatsResponse, platform string) Stats {
+	// Initialize new map with stat details objects based on playlist, always including the 3 party modes.
+	playlists := make(map[string]*StatDetails)
+	for _, group := range []string{Solo, Duo, Squad} {
+		playlists[strings.TrimPrefix(group, "_")] = &StatDetails{}
 	}
-}
-
-// mapStats takes a statsResponse object and converts it into a Stats object. It parses the JSON returned from Epic
-// regarding a player's stats, and maps it accordingly based on party type, as well as calculates several useful ratios.
-func (s *Session) mapStats(records *statsResponse, platform string) Stats {
-	// Initialize new map with stat details objects based on group type.
-	groups := make(map[string]*statDetails)
-	groups[Solo] = &statDetails{}
-	groups[Duo] = &statDetails{}
-	groups[Squad] = &statDetails{}
 
-	// Loop through the stats for a specific user properly sorting and organizing by group type into their own objects.
+	// Loop through the stats for a specific user properly sorting and organizing by playlist into their own objects.
+	unmapped := make(map[string]int)
 	for _, record := range *records {
-		switch {
-		case strings.Contains(record.Name, "placetop1_"+platform):
-			groups[getStatType(record.Name)].Wins = record.Value
-		case strings.Contains(record.Name, "placetop3_"+platform):
-			groups[getStatType(record.Name)].Top3 = record.Value
-		case strings.Contains(record.Name, "placetop5_"+platform):
-			groups[getStatType(record.Name)].Top5 = record.Value
-		case strings.Contains(record.Name, "placetop6_"+platform):
-			groups[getStatType(record.Name)].Top6 = record.Value
-		case strings.Contains(record.Name, "placetop10_"+platform):
-			groups[getStatType(record.Name)].Top10 = record.Value
-		case strings.Contains(record.Name, "placetop12_"+platform):
-			groups[getStatType(record.Name)].Top12 = record.Value
-		case strings.Contains(record.Name, "placetop25_"+platform):
-			groups[getStatType(record.Name)].Top25 = record.Value
-		case strings.Contains(record.Name, "matchesplayed_"+platform):
-			groups[getStatType(record.Name)].Matches = record.Value
-		case strings.Contains(record.Name, "kills_"+platform):
-			groups[getStatType(record.Name)].Kills = record.Value
-		case strings.Contains(record.Name, "score_"+platform):
-			groups[getStatType(record.Name)].Score = record.Value
-		case strings.Contains(record.Name, "minutesplayed_"+platform):
-			groups[getStatType(record.Name)].MinutesPlayed = record.Value
+		if record.Key == (StatKey{}) {
+			unmapped[record.Name] = record.Value
+			continue
+		}
+		if record.Key.Platform != platform {
+			continue
+		}
+
+		details, ok := playlists[record.Key.Playlist]
+		if !ok {
+			details = &StatDetails{}
+			playlists[record.Key.Playlist] = details
+		}
+		if !setStat(details, record.Key.Stat, record.Value) {
+			unmapped[record.Name] = record.Value
 		}
 	}
 
-	// Build new return object using the prepared map data.
-	ret := Stats{
-		Solo:  *groups[Solo],
-		Duo:   *groups[Duo],
-		Squad: *groups[Squad],
+	// Calculate additional information such as kill/death ratios, win percentages, etc. for every playlist.
+	ret := Stats{ByPlaylist: make(map[string]StatDetails, len(playlists))}
+	for name, details := range playlists {
+		calculateStatsRatios(details)
+		ret.ByPlaylist[name] = *details
+	}
+	if len(unmapped) > 0 {
+		ret.Unmapped = unmapped
 	}
 
-	// Calculate additional information such as kill/death ratios, win percentages, etc.
-	calculateStatsRatios(&ret.Solo)
-	calculateStatsRatios(&ret.Duo)
-	calculateStatsRatios(&ret.Squad)
+	// Build the party modes from the prepared playlist data.
+	ret.Solo = ret.ByPlaylist[strings.TrimPrefix(Solo, "_")]
+	ret.Duo = ret.ByPlaylist[strings.TrimPrefix(Duo, "_")]
+	ret.Squad = ret.ByPlaylist[strings.TrimPrefix(Squad, "_")]
 
 	// Return built Stats object.
 	return ret
 }
 
-// calculateStatsRatios takes a party-specific statDetails object and performs ratio calculations on specific data to
-// provide kill death ratio, win percentage, and kills per minute/match.
-func calculateStatsRatios(s *statDetails) {
-	s.KillDeathRatio = strconv.FormatFloat(ratio(s.Kills, s.Matches-s.Wins), 'f', 2, 64)
-	s.WinPercentage = strconv.FormatFloat(ratio(s.Wins, s.Matches)*100, 'f', 2, 64)
-	s.KillsPerMinute = strconv.FormatFloat(ratio(s.Kills, s.MinutesPlayed), 'f', 2, 64)
-	s.KillsPerMatch = strconv.FormatFloat(ratio(s.Kills, s.Matches), 'f', 2, 64)
+// Ratios holds ratios calculated from the statistics of a group mode, at full precision.
+type Ratios struct {
+	KillDeath      float64
+	WinPercentage  float64
+	KillsPerMinute float64
+	KillsPerMatch  float64
+}
+
+// Ratios performs ratio calculations on the statistics to provide kill death ratio, win percentage, and kills per
+// minute/match.
+func (s StatDetails) Ratios() Ratios {
+	return Ratios{
+		KillDeath:      ratio(s.Kills, s.Matches-s.Wins),
+		WinPercentage:  ratio(s.Wins, s.Matches) * 100,
+		KillsPerMinute: ratio(s.Kills, s.MinutesPlayed),
+		KillsPerMatch:  ratio(s.Kills, s.Matches),
+	}
+}
+
+// FormatRatio formats a ratio to 2 decimal places, as the ratio fields of StatDetails are.
+func FormatRatio(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+// calculateStatsRatios takes a party-specific StatDetails object and fills its ratio fields with the formatted
+// result of its Ratios.
+func calculateStatsRatios(s *StatDetails) {
+	r := s.Ratios()
+	s.KillDeathRatio = FormatRatio(r.KillDeath)
+	s.WinPercentage = FormatRatio(r.WinPercentage)
+	s.KillsPerMinute = FormatRatio(r.KillsPerMinute)
+	s.KillsPerMatch = FormatRatio(r.KillsPerMatch)
 }
 
 // ratio is a helper function to perform float division without causing a division by 0 panic.
@@ -307,6 +468,26 @@ type leaderboardResponse struct {
 // GetWinsLeaderboard returns the top 50 players and their rank position based on global wins for a specific platform,
 // and party/group type.
 func (s *Session) GetWinsLeaderboard(platform, groupType string) (*GlobalWinsLeaderboard, error) {
+	return s.GetWinsLeaderboardContext(context.Background(), platform, groupType)
+}
+
+// GetWinsLeaderboardContext is like GetWinsLeaderboard, but bound to the context provided.
+func (s *Session) GetWinsLeaderboardContext(ctx context.Context, platform, groupType string) (
+	_ *GlobalWinsLeaderboard, err error) {
+	ctx, span := s.startSpan(ctx, "GetWinsLeaderboard")
+	defer endSpan(span, &err)
+	span.SetAttribute("platform", platform)
+	span.SetAttribute("group_type", groupType)
+
+	if err := s.acquire(); err != nil {
+		return nil, err
+	}
+	defer s.release()
+
+	if !validPlatform(platform) {
+		return nil, ErrInvalidPlatform
+	}
+
 	qp := url.Values{}
 	qp.Add("ownertype", "1")     // unknown
 	qp.Add("pageNumber", "0")    // not implemented in-game?
@@ -314,23 +495,21 @@ func (s *Session) GetWinsLeaderboard(platform, groupType string) (*GlobalWinsLea
 
 	// Prepare new request to obtain leaderboard information. Epic literally expects an empty JSON array as input in
 	// order for the request to be valid, hence sending a buffer of an empty array.
-	u := fmt.Sprintf(winsLeaderboardURL, platform, groupType) + "?" + qp.Encode()
-	req, err := s.client.NewRequest(http.MethodPost, u, bytes.NewBufferString("[]"))
+	u := s.client.endpoints.Fortnite + fmt.Sprintf(winsLeaderboardPath, platform, groupType) + "?" + qp.Encode()
+	body := bytes.NewBufferString("[]")
+	req, err := s.authorizedRequest(ctx, winsLeaderboardEndpoint, http.MethodPost, u, body)
 	if err != nil {
 		return nil, err
 	}
 
-	// Use access token and set content type to JSON since we're ending an empty array with the request.
-	req.Header.Set("Authorization", fmt.Sprintf("%v %v", AuthBearer, s.AccessToken))
+	// Set content type to JSON since we're sending an empty array with the request.
 	req.Header.Set("Content-Type", "application/json")
 
 	// Perform request and collect response data into leaderboardResponse object.
 	lr := &leaderboardResponse{}
-	resp, err := s.client.Do(req, lr)
-	if err != nil {
+	if err := s.do(req, lr); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
 	// Loop through entries received building an array of account IDs.
 	var accountIDs []string
@@ -339,7 +518,7 @@ func (s *Session) GetWinsLeaderboard(platform, groupType string) (*GlobalWinsLea
 	}
 
 	// Send account IDs off to be queried so we can collect their human-readable display name (Epic Username).
-	acctInfoMap, err := s.getAccountNames(accountIDs)
+	acctInfoMap, err := s.getAccountNames(ctx, accountIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -362,7 +541,12 @@ func (s *Session) GetWinsLeaderboard(platform, groupType string) (*GlobalWinsLea
 
 // getAccountNames is a helper to query a bulk amount of account IDs to get additional information on them, in
 // particular, their username.
-func (s *Session) getAccountNames(ids []string) (map[string]string, error) {
+func (s *Session) getAccountNames(ctx context.Context, ids []string) (map[string]string, error) {
+	ret := make(map[string]string)
+	if len(ids) == 0 {
+		return ret, nil
+	}
+
 	// Build query parameter string based on account IDs supplied.
 	var p string
 	for _, id := range ids {
@@ -372,24 +556,19 @@ func (s *Session) getAccountNames(ids []string) (map[string]string, error) {
 	p = p[:len(p)-1] // Strip trailing '&'.
 
 	// Prepare new request to the persona server for information about these accounts.
-	req, err := s.client.NewRequest(http.MethodGet, accountInfoURL+"?"+p, nil)
+	u := s.client.endpoints.Account + accountInfoPath + "?" + p
+	req, err := s.authorizedRequest(ctx, accountInfoEndpoint, http.MethodGet, u, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	// Set authorization header to use our access token.
-	req.Header.Set("Authorization", fmt.Sprintf("%v %v", AuthBearer, s.AccessToken))
-
 	// Perform query and collect response into an array of lookupResponse objects.
 	var data []lookupResponse
-	resp, err := s.client.Do(req, &data)
-	if err != nil {
+	if err := s.do(req, &data); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// Prepare return map where we will map the account ID to the newly-collected username (DisplayName).
-	ret := make(map[string]string)
+	// Fill return map where we will map the account ID to the newly-collected username (DisplayName).
 	for _, a := range data {
 		ret[a.ID] = a.DisplayName
 	}
@@ -404,25 +583,34 @@ type statusResponse []struct {
 	MaintenanceURI interface{} `json:"maintenanceUri"`
 }
 
-// CheckStatus checks the status of the Fortnite game service. Will return false with error containing the status
-// message from Epic.
+// CheckStatus checks the status of the Fortnite game service. Will return false with an error wrapping ErrServiceDown
+// containing the status message from Epic.
 func (s *Session) CheckStatus() (bool, error) {
+	return s.CheckStatusContext(context.Background())
+}
+
+// CheckStatusContext is like CheckStatus, but bound to the context provided.
+func (s *Session) CheckStatusContext(ctx context.Context) (_ bool, err error) {
+	ctx, span := s.startSpan(ctx, "CheckStatus")
+	defer endSpan(span, &err)
+
+	if err := s.acquire(); err != nil {
+		return false, err
+	}
+	defer s.release()
+
 	// Prepare new request.
-	req, err := s.client.NewRequest(http.MethodGet, serverStatusURL, nil)
+	u := s.client.endpoints.Lightswitch + serverStatusPath
+	req, err := s.authorizedRequest(ctx, serverStatusEndpoint, http.MethodGet, u, nil)
 	if err != nil {
 		return false, err
 	}
 
-	// Set authorization header to use access token.
-	req.Header.Set("Authorization", fmt.Sprintf("%v %v", AuthBearer, s.AccessToken))
-
 	// Perform request and decode response into a statusResponse object.
 	var sr statusResponse
-	resp, err := s.client.Do(req, &sr)
-	if err != nil {
+	if err := s.do(req, &sr); err != nil {
 		return false, err
 	}
-	defer resp.Body.Close()
 
 	// Ensure at least one value of the array has been provided to prevent panic.
 	if len(sr) == 0 {
@@ -435,6 +623,6 @@ func (s *Session) CheckStatus() (bool, error) {
 		// Never return the message here since it doesn't seem to be removed when the server resume online status.
 		return true, nil
 	default:
-		return false, errors.New("service is down: " + sr[0].Message)
+		return false, fmt.Errorf("%w: %v", ErrServiceDown, sr[0].Message)
 	}
 }
@@ -1,10 +1,183 @@
 package fornitego
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Error is an error defined by this package. Errors returned may be compared against the variables below using
+// errors.Is.
 type Error struct{ e string }
 
 func (e *Error) Error() string {
 	return e.e
 }
 
-// ErrNotFound is returned when we receive a 404 when attempting to query a player.
-var ErrNotFound = Error{"Character not found."}
+// ErrNotFound is matched by an *APIError when we receive a 404 when attempting to query a player.
+var ErrNotFound = &Error{"Character not found."}
+
+var (
+	// ErrPlayerNotFound is returned when no account exists for the player queried.
+	ErrPlayerNotFound = &Error{"player not found"}
+	// ErrNoStats is returned when a player has no statistics recorded.
+	ErrNoStats = &Error{"no statistics found"}
+	// ErrInvalidPlatform is returned when a platform other than PC, Xbox or PS4 is specified.
+	ErrInvalidPlatform = &Error{"invalid platform specified"}
+	// ErrInvalidStatKey is returned when the name of a statistic is not in the form Epic records them under.
+	ErrInvalidStatKey = &Error{"invalid stat key"}
+	// ErrServiceDown is returned when the Fortnite game service reports that it is down.
+	ErrServiceDown = &Error{"service is down"}
+	// ErrSessionClosed is returned when attempting to use a Session after it has been closed.
+	ErrSessionClosed = &Error{"session is closed"}
+)
+
+// Authentication steps reported by AuthError.
+const (
+	AuthStepPassword          = "password grant"
+	AuthStepAuthorizationCode = "authorization_code grant"
+	AuthStepExchange          = "exchange"
+	AuthStepExchangeCode      = "exchange_code grant"
+	AuthStepTwoFactor         = "otp grant"
+	AuthStepRefresh           = "refresh_token grant"
+	AuthStepDeviceAuth        = "device_auth grant"
+)
+
+// AuthError is returned when a step of the authentication flow with Epic fails. The underlying error is retained and
+// can be inspected with errors.Is and errors.As.
+type AuthError struct {
+	Step string
+	Err  error
+}
+
+func (e *AuthError) Error() string {
+	return "authentication failed during " + e.Step + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error which caused the authentication step to fail.
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// Epic error codes, as found in the errorCode field of an APIError.
+const (
+	errorCodeAccountNotFound = "errors.com.epicgames.account.account_not_found"
+	errorCodeNotFound        = "errors.com.epicgames.common.not_found"
+	errorCodeInvalidToken    = "errors.com.epicgames.common.oauth.invalid_token"
+	errorCodeTokenFailed     = "errors.com.epicgames.common.authentication.token_verification_failed"
+	errorCodeInvalidRefresh  = "errors.com.epicgames.account.auth_token.invalid_refresh_token"
+	errorCodeThrottled       = "errors.com.epicgames.common.throttled"
+	errorCodePrivateStats    = "errors.com.epicgames.fortnite.stats_private"
+	errorCodeAccountBanned   = "errors.com.epicgames.account.account_not_active"
+)
+
+// Sentinel errors to compare an *APIError against with errors.Is.
+var (
+	// ErrInvalidToken is matched when Epic rejects the access token used.
+	ErrInvalidToken = &Error{"invalid access token"}
+	// ErrRateLimited is matched when Epic throttles requests.
+	ErrRateLimited = &Error{"rate limited"}
+	// ErrPrivateStats is matched when a player's statistics are not public.
+	ErrPrivateStats = &Error{"player statistics are private"}
+	// ErrBanned is matched when the account is banned or otherwise inactive.
+	ErrBanned = &Error{"account is banned"}
+)
+
+// APIError is returned when Epic responds to a request with an unsuccessful status code. Where the response holds
+// Epic's standard error object, its fields are decoded. Use errors.Is with ErrNotFound, ErrInvalidToken,
+// ErrRateLimited, ErrPrivateStats and ErrBanned to check for common cases.
+type APIError struct {
+	StatusCode         int      `json:"-"`
+	Body               []byte   `json:"-"`
+	ErrorCode          string   `json:"errorCode"`
+	ErrorMessage       string   `json:"errorMessage"`
+	MessageVars        []string `json:"messageVars"`
+	NumericErrorCode   int      `json:"numericErrorCode"`
+	OriginatingService string   `json:"originatingService"`
+	Intent             string   `json:"intent"`
+	OAuthError         string   `json:"error"` // OAuth error code returned by the token endpoint, such as invalid_grant.
+}
+
+// newAPIError builds an APIError from the status code and body of an unsuccessful response.
+func newAPIError(statusCode int, body []byte) *APIError {
+	e := &APIError{}
+
+	// Not every response carries an error object, in which case only the status and body are retained.
+	if err := json.Unmarshal(body, e); err != nil {
+		e = &APIError{}
+	}
+	e.StatusCode = statusCode
+	e.Body = body
+
+	return e
+}
+
+func (e *APIError) Error() string {
+	if e.ErrorCode == "" {
+		return fmt.Sprintf("unsuccessful response returned: %v %v", e.StatusCode, string(e.Body))
+	}
+
+	return fmt.Sprintf("unsuccessful response returned: %v %v: %v", e.StatusCode, e.ErrorCode, e.ErrorMessage)
+}
+
+// Is reports whether the error matches one of the sentinel errors for common cases.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || e.ErrorCode == errorCodeNotFound ||
+			e.ErrorCode == errorCodeAccountNotFound
+	case ErrInvalidToken:
+		return e.StatusCode == http.StatusUnauthorized || e.ErrorCode == errorCodeInvalidToken ||
+			e.ErrorCode == errorCodeTokenFailed
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests || e.ErrorCode == errorCodeThrottled
+	case ErrPrivateStats:
+		return e.ErrorCode == errorCodePrivateStats
+	case ErrBanned:
+		return e.ErrorCode == errorCodeAccountBanned
+	default:
+		return false
+	}
+}
+
+// errorCodeMFARequired is the error code Epic responds with when an account requires a two-factor authentication code
+// to complete a login.
+const errorCodeMFARequired = "errors.com.epicgames.common.two_factor_authentication.required"
+
+// mfaChallenge is the additional information Epic includes in an error requiring two-factor authentication.
+type mfaChallenge struct {
+	Challenge string `json:"challenge"`
+	Metadata  struct {
+		TwoFactorMethod  string   `json:"twoFactorMethod"`
+		AlternateMethods []string `json:"alternateMethods"`
+	} `json:"metadata"`
+}
+
+// MFARequiredError is returned when logging in with a password to an account which has two-factor authentication
+// enabled. The login can be completed by submitting the one-time code sent to the user with Complete.
+type MFARequiredError struct {
+	Challenge        string
+	Method           string
+	AlternateMethods []string
+	Message          string
+
+	session       *Session
+	launcherToken string
+}
+
+func (e *MFARequiredError) Error() string {
+	return "two-factor authentication required: " + e.Message
+}
+
+// Complete finishes the login which required two-factor authentication by submitting the one-time code provided, and
+// returns the resulting authenticated Session.
+func (e *MFARequiredError) Complete(ctx context.Context, code string) (*Session, error) {
+	t, err := completeTwoFactor(ctx, e.session.client, e.Challenge, code, e.launcherToken, e.session.gameToken)
+	if err != nil {
+		return nil, err
+	}
+
+	e.session.start(ctx, t)
+	return e.session, nil
+}
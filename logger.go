@@ -0,0 +1,25 @@
+package fornitego
+
+// Logger is a leveled, structured logger used by a Session to report on its activity. Arguments following the message
+// are alternating keys and values, as with log/slog, whose *slog.Logger satisfies this interface.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// WithLogger sets the Logger a Session reports its activity to. By default, nothing is logged.
+func WithLogger(l Logger) Option {
+	return func(s *Session) {
+		s.client.logger = l
+	}
+}
+
+// nopLogger is a Logger which discards everything logged.
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{}) {}
+func (nopLogger) Info(msg string, args ...interface{})  {}
+func (nopLogger) Warn(msg string, args ...interface{})  {}
+func (nopLogger) Error(msg string, args ...interface{}) {}
@@ -0,0 +1,118 @@
+package fornitego
+
+import (
+	"expvar"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Metrics receives measurements of a Session's activity, for export to a monitoring system. Implementations may be
+// backed by Prometheus client_golang collectors, expvar as with NewExpvarMetrics, or anything else. Methods may be
+// called concurrently.
+type Metrics interface {
+	// ObserveRequest records the outcome of an attempt at a request to an endpoint. The status is zero if no response
+	// was received.
+	ObserveRequest(endpoint string, status int, latency time.Duration)
+	// IncRetry records a request to an endpoint being retried.
+	IncRetry(endpoint string)
+	// ObserveRateLimitWait records time a request spent waiting on the rate limit of a service.
+	ObserveRateLimitWait(service Service, wait time.Duration)
+	// IncTokenRenewal records an attempt to renew the access token, and its outcome.
+	IncTokenRenewal(kind RenewalKind)
+	// SetTokenExpiry records the time remaining until the access token expires.
+	SetTokenExpiry(remaining time.Duration)
+}
+
+// WithMetrics sets the Metrics a Session records measurements of its activity to. By default, nothing is recorded.
+func WithMetrics(m Metrics) Option {
+	return func(s *Session) {
+		s.client.metrics = m
+	}
+}
+
+// nopMetrics is a Metrics which discards every measurement.
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveRequest(endpoint string, status int, latency time.Duration) {}
+func (nopMetrics) IncRetry(endpoint string)                                          {}
+func (nopMetrics) ObserveRateLimitWait(service Service, wait time.Duration)          {}
+func (nopMetrics) IncTokenRenewal(kind RenewalKind)                                  {}
+func (nopMetrics) SetTokenExpiry(remaining time.Duration)                            {}
+
+// latencyBuckets are the upper bounds, in seconds, of the histogram buckets latencies are counted in by ExpvarMetrics.
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// ExpvarMetrics is a Metrics publishing its measurements as expvar variables, served at /debug/vars by the expvar
+// package's handler.
+type ExpvarMetrics struct {
+	requests       *expvar.Map // Count per endpoint and status, keyed "endpoint status".
+	requestLatency *expvar.Map // Histogram per endpoint.
+	retries        *expvar.Map // Count per endpoint.
+	rateLimitWait  *expvar.Map // Histogram per service.
+	tokenRenewals  *expvar.Map // Count per outcome.
+	tokenExpiry    *expvar.Float
+
+	mux sync.Mutex // Guards creation of histograms.
+}
+
+// NewExpvarMetrics returns an ExpvarMetrics publishing its variables with names beginning with the prefix provided.
+// As expvar variables are global, the prefix must be unique within the program.
+func NewExpvarMetrics(prefix string) *ExpvarMetrics {
+	return &ExpvarMetrics{
+		requests:       expvar.NewMap(prefix + "requests_total"),
+		requestLatency: expvar.NewMap(prefix + "request_latency_seconds"),
+		retries:        expvar.NewMap(prefix + "retries_total"),
+		rateLimitWait:  expvar.NewMap(prefix + "rate_limit_wait_seconds"),
+		tokenRenewals:  expvar.NewMap(prefix + "token_renewals_total"),
+		tokenExpiry:    expvar.NewFloat(prefix + "token_expiry_seconds"),
+	}
+}
+
+// ObserveRequest counts the request and records its latency.
+func (m *ExpvarMetrics) ObserveRequest(endpoint string, status int, latency time.Duration) {
+	m.requests.Add(endpoint+" "+strconv.Itoa(status), 1)
+	m.observe(m.requestLatency, endpoint, latency)
+}
+
+// IncRetry counts the retry.
+func (m *ExpvarMetrics) IncRetry(endpoint string) {
+	m.retries.Add(endpoint, 1)
+}
+
+// ObserveRateLimitWait records the time waited.
+func (m *ExpvarMetrics) ObserveRateLimitWait(service Service, wait time.Duration) {
+	m.observe(m.rateLimitWait, string(service), wait)
+}
+
+// IncTokenRenewal counts the renewal by its outcome.
+func (m *ExpvarMetrics) IncTokenRenewal(kind RenewalKind) {
+	m.tokenRenewals.Add(kind.String(), 1)
+}
+
+// SetTokenExpiry sets the time remaining until the access token expires.
+func (m *ExpvarMetrics) SetTokenExpiry(remaining time.Duration) {
+	m.tokenExpiry.Set(remaining.Seconds())
+}
+
+// observe records a duration in the histogram held under the key provided, creating it if needed. Each histogram
+// holds a cumulative count per bucket, along with the total count and sum.
+func (m *ExpvarMetrics) observe(histograms *expvar.Map, key string, d time.Duration) {
+	m.mux.Lock()
+	h, ok := histograms.Get(key).(*expvar.Map)
+	if !ok {
+		h = new(expvar.Map).Init()
+		histograms.Set(key, h)
+	}
+	m.mux.Unlock()
+
+	secs := d.Seconds()
+	for _, le := range latencyBuckets {
+		if secs <= le {
+			h.Add("le_"+strconv.FormatFloat(le, 'f', -1, 64), 1)
+		}
+	}
+	h.Add("le_inf", 1)
+	h.Add("count", 1)
+	h.AddFloat("sum", secs)
+}
@@ -0,0 +1,141 @@
+package fornitego
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RoundTripFunc processes a single HTTP request, returning its response.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// Middleware wraps the processing of requests made to Epic's API, such as to modify requests or observe responses.
+// Middleware is applied to every attempt at a request, including retries. It should not modify the request provided,
+// but a clone of it.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware adds middleware to the chain requests made to Epic's API pass through. Middleware given first is
+// outermost, seeing requests first and responses last.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(s *Session) {
+		s.client.middleware = append(s.client.middleware, mw...)
+	}
+}
+
+// WithBeforeHook adds a function called before every attempt at a request made to Epic's API, with the name of the
+// endpoint requested.
+func WithBeforeHook(fn func(endpoint string, req *http.Request)) Option {
+	return func(s *Session) {
+		s.client.before = append(s.client.before, fn)
+	}
+}
+
+// WithAfterHook adds a function called after every attempt at a request made to Epic's API, with the name of the
+// endpoint requested and the outcome of the attempt.
+func WithAfterHook(fn func(endpoint string, req *http.Request, resp *http.Response, err error)) Option {
+	return func(s *Session) {
+		s.client.after = append(s.client.after, fn)
+	}
+}
+
+// EndpointName returns the name of the Epic API endpoint a request made by a Session is for, such as "account_stats"
+// or "oauth_token". Returns an empty string for requests prepared by Client.NewRequest.
+func EndpointName(req *http.Request) string {
+	return endpointOf(req).name
+}
+
+// LoggingMiddleware returns Middleware logging every request made and its outcome to the Logger provided, at debug
+// level. Credentials, such as the Authorization header and the access token in the URL of a kill request, are redacted.
+func LoggingMiddleware(l Logger) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			l.Debug("sending request", "endpoint", EndpointName(req), "method", req.Method, "url", redactURL(req.URL),
+				"headers", redactHeaders(req.Header))
+
+			resp, err := next(req)
+			if err != nil {
+				l.Debug("request failed", "endpoint", EndpointName(req), "latency", time.Since(start),
+					"error", redactError(err))
+				return nil, err
+			}
+
+			l.Debug("received response", "endpoint", EndpointName(req), "status", resp.StatusCode,
+				"latency", time.Since(start))
+			return resp, nil
+		}
+	}
+}
+
+// HeaderMiddleware returns Middleware setting the headers provided on every request made, such as for tracing.
+func HeaderMiddleware(h http.Header) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			for k, v := range h {
+				req.Header[k] = append([]string(nil), v...)
+			}
+
+			return next(req)
+		}
+	}
+}
+
+// redactHeaders returns a copy of the headers provided with the credentials of the Authorization header removed.
+func redactHeaders(h http.Header) http.Header {
+	r := h.Clone()
+	if v := r.Get("Authorization"); v != "" {
+		scheme := strings.SplitN(v, " ", 2)[0]
+		r.Set("Authorization", scheme+" [REDACTED]")
+	}
+
+	return r
+}
+
+// redactURL returns the URL provided as a string, with the access token the path of a kill request ends with removed.
+func redactURL(u *url.URL) string {
+	if !strings.HasPrefix(u.Path, killSessionPath+"/") {
+		return u.String()
+	}
+
+	r := *u
+	r.Path = killSessionPath + "/[REDACTED]"
+	r.RawPath = r.Path
+	return r.String()
+}
+
+// redactError returns the error provided, with the URL of a failed request redacted by redactURL.
+func redactError(err error) error {
+	ue, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+
+	u, perr := url.Parse(ue.URL)
+	if perr != nil {
+		return err
+	}
+
+	return &url.Error{Op: ue.Op, URL: redactURL(u), Err: ue.Err}
+}
+
+// send passes a request through the client's hooks and middleware before processing it with the HTTP client.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	name := EndpointName(req)
+	for _, fn := range c.before {
+		fn(name, req)
+	}
+
+	rt := RoundTripFunc(c.client.Do)
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		rt = c.middleware[i](rt)
+	}
+	resp, err := rt(req)
+
+	for _, fn := range c.after {
+		fn(name, req, resp, err)
+	}
+
+	return resp, err
+}
@@ -0,0 +1,97 @@
+package fornitego
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option configures a Session upon its creation.
+type Option func(*Session)
+
+// WithTokenStore sets the TokenStore the session persists its tokens to. Upon creation, a session will resume from a
+// token in the store which has not yet expired, before falling back to authenticating with its TokenSource.
+func WithTokenStore(store TokenStore) Option {
+	return func(s *Session) {
+		s.store = store
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to make requests to Epic's API. A copy of the client is used, so options
+// configuring the transport or timeout, which should be given after this one, leave the client provided untouched. A
+// nil client restores the default.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(s *Session) {
+		if hc == nil {
+			s.client.client = &http.Client{Timeout: defaultTimeout}
+			return
+		}
+
+		c := *hc
+		s.client.client = &c
+	}
+}
+
+// WithTransport sets the RoundTripper used to make requests to Epic's API.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(s *Session) {
+		s.client.client.Transport = rt
+	}
+}
+
+// WithTimeout sets the time limit for each request made to Epic's API. A timeout of zero means no timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(s *Session) {
+		s.client.client.Timeout = d
+	}
+}
+
+// WithProxy routes requests made to Epic's API through the proxy at the URL provided. Replaces any transport
+// previously set which is not an *http.Transport.
+func WithProxy(proxy *url.URL) Option {
+	return func(s *Session) {
+		t, ok := s.client.client.Transport.(*http.Transport)
+		if !ok {
+			t = http.DefaultTransport.(*http.Transport)
+		}
+		t = t.Clone()
+		t.Proxy = http.ProxyURL(proxy)
+
+		s.client.client.Transport = t
+	}
+}
+
+// WithUserAgent appends a suffix to the User-Agent sent with each request, identifying the application using this
+// package.
+func WithUserAgent(suffix string) Option {
+	return func(s *Session) {
+		s.client.userAgent = userAgent + " " + suffix
+	}
+}
+
+// WithHeader sets a header to be sent with each request made to Epic's API. Headers set by this package for a request,
+// such as Authorization, take precedence.
+func WithHeader(key, value string) Option {
+	return func(s *Session) {
+		s.client.headers.Set(key, value)
+	}
+}
+
+// WithEndpoints sets the base URLs of the Epic services requests are made to. Any field left empty keeps its default
+// from DefaultEndpoints.
+func WithEndpoints(e Endpoints) Option {
+	return func(s *Session) {
+		if e.Account != "" {
+			s.client.endpoints.Account = e.Account
+		}
+		if e.Persona != "" {
+			s.client.endpoints.Persona = e.Persona
+		}
+		if e.Lightswitch != "" {
+			s.client.endpoints.Lightswitch = e.Lightswitch
+		}
+		if e.Fortnite != "" {
+			s.client.endpoints.Fortnite = e.Fortnite
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package fornitego
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Service identifies an Epic service which requests are made to. Requests are rate limited per service.
+type Service string
+
+// Epic services
+const (
+	ServiceAccount     Service = "account"
+	ServicePersona     Service = "persona"
+	ServiceStats       Service = "stats"
+	ServiceLeaderboard Service = "leaderboard"
+	ServiceLightswitch Service = "lightswitch"
+)
+
+// WithRateLimit limits requests made to an Epic service to the rate given per second, allowing bursts of up to burst
+// requests, at least one. Requests exceeding the limit block until permitted, or their context is done. Regardless of
+// limits set, requests to a service are held back for as long as Epic asks via a Retry-After header when responding
+// with a 429.
+func WithRateLimit(svc Service, perSecond float64, burst int) Option {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return func(s *Session) {
+		b := s.client.limiter.bucket(svc)
+		b.mux.Lock()
+		b.rate = perSecond
+		b.burst = float64(burst)
+		b.tokens = float64(burst)
+		b.mux.Unlock()
+	}
+}
+
+// rateLimiter holds a token bucket for each Epic service.
+type rateLimiter struct {
+	mux     sync.Mutex
+	buckets map[Service]*bucket
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{buckets: make(map[Service]*bucket)}
+}
+
+// bucket returns the token bucket for the service provided, creating an unlimited one if none exists yet.
+func (l *rateLimiter) bucket(svc Service) *bucket {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	b, ok := l.buckets[svc]
+	if !ok {
+		b = &bucket{}
+		l.buckets[svc] = b
+	}
+
+	return b
+}
+
+// wait blocks until a request to the service provided is permitted, returning the time spent waiting.
+func (l *rateLimiter) wait(ctx context.Context, svc Service) (time.Duration, error) {
+	return l.bucket(svc).wait(ctx)
+}
+
+// pause holds back all requests to the service provided until the time given.
+func (l *rateLimiter) pause(svc Service, until time.Time) {
+	b := l.bucket(svc)
+	b.mux.Lock()
+	if until.After(b.blockedUntil) {
+		b.blockedUntil = until
+	}
+	b.mux.Unlock()
+}
+
+// bucket is a token bucket permitting requests at a steady rate with bursts. A rate of zero means unlimited.
+type bucket struct {
+	mux          sync.Mutex
+	rate         float64
+	burst        float64
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+// wait blocks until a token can be taken from the bucket, returning the time spent waiting.
+func (b *bucket) wait(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	for {
+		d := b.take(time.Now())
+		if d == 0 {
+			return time.Since(start), nil
+		}
+
+		t := time.NewTimer(d)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return time.Since(start), ctx.Err()
+		}
+	}
+}
+
+// take attempts to take a token from the bucket. Returns zero if successful, otherwise the time to wait before trying
+// again.
+func (b *bucket) take(now time.Time) time.Duration {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if now.Before(b.blockedUntil) {
+		return b.blockedUntil.Sub(now)
+	}
+	if b.rate <= 0 {
+		return 0
+	}
+
+	// Refill tokens for the time passed since last taken, up to the burst size.
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// retryAfter parses the Retry-After header of a response, given either in seconds or as an HTTP date. Returns zero if
+// absent or malformed.
+func retryAfter(resp *http.Response, now time.Time) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+
+	return 0
+}
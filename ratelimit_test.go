@@ -0,0 +1,85 @@
+package fornitego
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2018, 6, 26, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "5", want: 5 * time.Second},
+		{value: "0", want: 0},
+		{value: "-3", want: 0},
+		{value: now.Add(30 * time.Second).Format(http.TimeFormat), want: 30 * time.Second},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0}, // In the past.
+		{value: "soon", want: 0},
+		{value: "1.5", want: 0},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.value != "" {
+			resp.Header.Set("Retry-After", tt.value)
+		}
+
+		if got := retryAfter(resp, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestBucketTake(t *testing.T) {
+	now := time.Date(2018, 6, 26, 8, 0, 0, 0, time.UTC)
+	b := &bucket{rate: 2, burst: 2, tokens: 2}
+
+	// The burst is permitted immediately, after which requests wait for a token to refill.
+	for i := 0; i < 2; i++ {
+		if d := b.take(now); d != 0 {
+			t.Fatalf("take %v within burst waited %v", i, d)
+		}
+	}
+	if d := b.take(now); d != 500*time.Millisecond {
+		t.Fatalf("take beyond burst waited %v, want 500ms", d)
+	}
+	if d := b.take(now.Add(500 * time.Millisecond)); d != 0 {
+		t.Fatalf("take after refill waited %v", d)
+	}
+
+	// A pause holds back requests until it ends, regardless of tokens available.
+	b.blockedUntil = now.Add(10 * time.Second)
+	if d := b.take(now.Add(4 * time.Second)); d != 6*time.Second {
+		t.Fatalf("take while paused waited %v, want 6s", d)
+	}
+
+	// A bucket without a rate is unlimited.
+	u := &bucket{}
+	for i := 0; i < 100; i++ {
+		if d := u.take(now); d != 0 {
+			t.Fatalf("take from unlimited bucket waited %v", d)
+		}
+	}
+}
+
+func TestRateLimiterPause(t *testing.T) {
+	now := time.Date(2018, 6, 26, 8, 0, 0, 0, time.UTC)
+	l := newRateLimiter()
+
+	// A shorter pause does not cut short a longer one already in effect.
+	l.pause(ServiceStats, now.Add(10*time.Second))
+	l.pause(ServiceStats, now.Add(5*time.Second))
+	if d := l.bucket(ServiceStats).take(now); d != 10*time.Second {
+		t.Fatalf("take from paused service waited %v, want 10s", d)
+	}
+
+	// Other services are unaffected.
+	if d := l.bucket(ServiceAccount).take(now); d != 0 {
+		t.Fatalf("take from other service waited %v", d)
+	}
+}
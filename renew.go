@@ -0,0 +1,294 @@
+package fornitego
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Renewal tuning. The access token is checked at every interval, and renewed once its expiry falls within the renewal
+// window. Failed renewals are retried with exponential backoff, bounded by the maximum.
+const (
+	renewCheckInterval = 20 * time.Second
+	renewWindow        = time.Minute
+	renewBackoffMin    = 5 * time.Second
+	renewBackoffMax    = 5 * time.Minute
+)
+
+// RenewalKind describes the outcome of an attempt to renew a session's access token.
+type RenewalKind int
+
+// Renewal outcomes
+const (
+	// RenewalRefreshed is reported when the access token was renewed using the refresh token.
+	RenewalRefreshed RenewalKind = iota
+	// RenewalRelogin is reported when the refresh token had expired or was rejected, and a full login was performed
+	// instead.
+	RenewalRelogin
+	// RenewalFailed is reported when renewal failed. It will be retried after a backoff.
+	RenewalFailed
+)
+
+func (k RenewalKind) String() string {
+	switch k {
+	case RenewalRefreshed:
+		return "refreshed"
+	case RenewalRelogin:
+		return "relogin"
+	case RenewalFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// RenewalEvent describes an attempt by a session to renew its access token.
+type RenewalEvent struct {
+	Kind      RenewalKind
+	Time      time.Time
+	ExpiresAt string        // Expiry of the new access token, if renewed.
+	Err       error         // Cause of failure, if failed.
+	Failures  int           // Consecutive failures, including this one.
+	Backoff   time.Duration // Time until the next attempt, if failed.
+}
+
+// WithRenewalHook sets a function to be called after every attempt to renew the session's access token. The function
+// is called in a goroutine of its own, so it may call methods of the session such as Close, but may be called
+// concurrently and after renewal has stopped.
+func WithRenewalHook(fn func(RenewalEvent)) Option {
+	return func(s *Session) {
+		s.tokens.hook = fn
+	}
+}
+
+// tokenManager handles the automatic renewal of a session's access token in the background.
+type tokenManager struct {
+	s    *Session
+	hook func(RenewalEvent)
+
+	mux  sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+
+	failures    int
+	nextAttempt time.Time
+}
+
+// Start begins renewing the session's access token in the background. Does nothing if already started.
+func (m *tokenManager) Start() {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if m.stop != nil {
+		return
+	}
+
+	prev := m.done
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go m.run(prev, m.stop, m.done)
+}
+
+// Stop halts background renewal, waiting for any renewal in progress to finish or the context to be done, in which case
+// its error is returned. Does nothing if not started.
+func (m *tokenManager) Stop(ctx context.Context) error {
+	m.mux.Lock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+	done := m.done
+	m.mux.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run checks the session's access token at every interval until stopped, renewing it when its expiry is imminent. As
+// Stop may give up waiting before a previous run exits, the previous run is waited for before any checks are made.
+func (m *tokenManager) run(prev <-chan struct{}, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	if prev != nil {
+		<-prev
+	}
+
+	ticker := time.NewTicker(renewCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			m.check(now)
+		}
+	}
+}
+
+// check renews the session's access token if it expires within the renewal window, and no backoff is in effect.
+func (m *tokenManager) check(now time.Time) {
+	if now.Before(m.nextAttempt) {
+		return
+	}
+
+	t := m.s.Token()
+	m.s.observeExpiry(t.ExpiresAt)
+
+	// If the token does not expire within the renewal window, wait and try again.
+	if !expiresWithin(t.ExpiresAt, renewWindow) {
+		return
+	}
+
+	// Token expiry is imminent, renew.
+	var ev RenewalEvent
+	var err error
+	ev.Kind, err = m.s.renew(context.Background(), t.AccessToken)
+	if err != nil {
+		m.failures++
+		ev.Kind = RenewalFailed
+		ev.Err = err
+		ev.Backoff = backoff(renewBackoffMin, renewBackoffMax, m.failures)
+		m.nextAttempt = now.Add(ev.Backoff)
+		m.s.client.logger.Warn("token renewal unsuccessful", "account_id", m.s.Token().AccountID, "error", err,
+			"failures", m.failures, "backoff", ev.Backoff)
+	} else {
+		m.failures = 0
+		m.nextAttempt = time.Time{}
+		ev.ExpiresAt = m.s.Token().ExpiresAt
+		m.s.client.logger.Info("token renewed", "account_id", m.s.Token().AccountID, "kind", ev.Kind,
+			"expires_at", ev.ExpiresAt)
+	}
+
+	ev.Time = now
+	ev.Failures = m.failures
+	if m.hook != nil {
+		go m.hook(ev)
+	}
+}
+
+// renewal is a renewal of a session's access token in progress, shared between all callers awaiting it.
+type renewal struct {
+	done chan struct{}
+	kind RenewalKind
+	err  error
+}
+
+// renew renews the session's access token, unless it has already been replaced since the caller observed the stale
+// token provided. Concurrent callers share a single renewal, which is not cancelled should the context of the caller
+// which began it be done. If the refresh token has expired or is rejected by Epic, a full login is performed from the
+// session's TokenSource. Returns ErrSessionClosed once the session has been killed.
+func (s *Session) renew(ctx context.Context, stale string) (RenewalKind, error) {
+	s.mux.Lock()
+	if s.killed {
+		s.mux.Unlock()
+		return RenewalFailed, ErrSessionClosed
+	}
+	if s.AccessToken != stale {
+		s.mux.Unlock()
+		return RenewalRefreshed, nil
+	}
+
+	r := s.renewal
+	if r == nil {
+		r = &renewal{done: make(chan struct{})}
+		s.renewal = r
+		go s.performRenewal(context.WithoutCancel(ctx), r)
+	}
+	s.mux.Unlock()
+
+	select {
+	case <-r.done:
+		return r.kind, r.err
+	case <-ctx.Done():
+		return RenewalFailed, ctx.Err()
+	}
+}
+
+// awaitRenewal waits for any renewal of the session's access token in progress to finish, or the context to be done.
+func (s *Session) awaitRenewal(ctx context.Context) {
+	s.mux.Lock()
+	r := s.renewal
+	s.mux.Unlock()
+
+	if r == nil {
+		return
+	}
+
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+	}
+}
+
+// performRenewal carries out a renewal begun by renew, and notifies those awaiting it once finished.
+func (s *Session) performRenewal(ctx context.Context, r *renewal) {
+	t := s.Token()
+
+	var nt *Token
+	if expiresWithin(t.RefreshExpiresAt, 0) {
+		r.kind = RenewalRelogin
+		nt, r.err = s.source.Token(ctx, s.client)
+	} else {
+		r.kind = RenewalRefreshed
+		nt, r.err = RefreshTokenSource(t.RefreshToken, s.gameToken).Token(ctx, s.client)
+
+		// A refresh token revoked before its expiry will never be accepted again, so log in afresh.
+		if refreshRejected(r.err) {
+			s.client.logger.Warn("refresh token rejected", "account_id", t.AccountID, "error", r.err)
+			r.kind = RenewalRelogin
+			nt, r.err = s.source.Token(ctx, s.client)
+		}
+	}
+	if r.err == nil && !s.updateToken(ctx, nt) {
+		r.err = ErrSessionClosed
+	}
+
+	if r.err != nil {
+		s.client.metrics.IncTokenRenewal(RenewalFailed)
+	} else {
+		s.client.metrics.IncTokenRenewal(r.kind)
+	}
+
+	s.mux.Lock()
+	s.renewal = nil
+	s.mux.Unlock()
+
+	close(r.done)
+}
+
+// refreshRejected reports whether an error returned by the refresh grant indicates that Epic no longer accepts the
+// refresh token, rather than a transient failure.
+func refreshRejected(err error) bool {
+	var ae *APIError
+	if !errors.As(err, &ae) {
+		return false
+	}
+
+	return errors.Is(ae, ErrInvalidToken) || ae.ErrorCode == errorCodeInvalidRefresh ||
+		(ae.StatusCode == http.StatusBadRequest && ae.OAuthError == "invalid_grant")
+}
+
+// backoff returns the delay before the next attempt after the given number of consecutive failures, doubling from min
+// up to max.
+func backoff(min, max time.Duration, failures int) time.Duration {
+	d := min
+	for i := 1; i < failures && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+
+	return d
+}
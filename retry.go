@@ -0,0 +1,103 @@
+package fornitego
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures how requests which fail transiently are retried. Only requests which are safe to repeat are
+// retried: those using GET, and the leaderboard request. Requests to obtain tokens, such as the password grant, are
+// never retried. Requests are retried upon network errors and 429, 502, 503 and 504 responses.
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts made, including the first. Values below 2 disable retries.
+	BaseDelay   time.Duration // Delay before the first retry, doubled for each retry after.
+	MaxDelay    time.Duration // Upper bound of the delay between retries. Zero means no bound.
+	Jitter      float64       // Fraction of the delay, between 0 and 1, randomly added or subtracted.
+}
+
+// DefaultRetryPolicy is the RetryPolicy used by a Session unless configured otherwise.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+	Jitter:      0.2,
+}
+
+// WithRetryPolicy sets the RetryPolicy used for requests made to Epic's API. A zero RetryPolicy disables retries.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(s *Session) {
+		s.client.retry = p
+	}
+}
+
+// allows reports whether the policy permits retrying the request provided to the given endpoint.
+func (p RetryPolicy) allows(req *http.Request, ep endpoint) bool {
+	if p.MaxAttempts < 2 {
+		return false
+	}
+
+	// The body must be able to be rewound for the request to be sent again.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	return req.Method == http.MethodGet || ep.idempotent
+}
+
+// delay returns the time to wait before the retry following the given attempt.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	max := p.MaxDelay
+	if max <= 0 {
+		max = math.MaxInt64 / 2 // Large enough to be no bound, while leaving room for jitter without overflowing.
+	}
+
+	d := backoff(p.BaseDelay, max, attempt)
+	if p.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(d))
+	}
+
+	return d
+}
+
+// retryable reports whether the error returned by an attempt at a request is transient.
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var ae *APIError
+	if errors.As(err, &ae) {
+		switch ae.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout:
+			return true
+		default:
+			return false
+		}
+	}
+
+	// Anything else originates from the transport, such as a connection reset.
+	return true
+}
+
+// sleep waits for the duration provided, returning false without waiting should the context be done first or its
+// deadline fall before the duration has passed.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return false
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
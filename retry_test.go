@@ -0,0 +1,79 @@
+package fornitego
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	tests := []struct {
+		policy RetryPolicy
+		want   []time.Duration // Delay after each attempt, starting from the first.
+	}{
+		{
+			policy: RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 3 * time.Second},
+			want:   []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second},
+		},
+		{
+			policy: RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second}, // No bound.
+			want:   []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		for i, want := range tt.want {
+			if got := tt.policy.delay(i + 1); got != want {
+				t.Errorf("%+v: delay(%v) = %v, want %v", tt.policy, i+1, got, want)
+			}
+		}
+	}
+
+	// Jitter keeps the delay within the fraction given of the delay without it.
+	p := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute, Jitter: 0.2}
+	for i := 0; i < 100; i++ {
+		if d := p.delay(1); d < 800*time.Millisecond || d > 1200*time.Millisecond {
+			t.Fatalf("delay with jitter = %v, want within 20%% of 1s", d)
+		}
+	}
+}
+
+func TestRetryPolicyAllows(t *testing.T) {
+	request := func(method string, body bool) *http.Request {
+		if !body {
+			req, _ := http.NewRequest(method, "https://example.com", nil)
+			return req
+		}
+		req, _ := http.NewRequest(method, "https://example.com", bytes.NewBufferString("grant_type=password"))
+		return req
+	}
+
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		req    *http.Request
+		ep     endpoint
+		want   bool
+	}{
+		{"get", DefaultRetryPolicy, request(http.MethodGet, false), accountStatsEndpoint, true},
+		{"idempotent post", DefaultRetryPolicy, request(http.MethodPost, true), winsLeaderboardEndpoint, true},
+		{"password grant", DefaultRetryPolicy, request(http.MethodPost, true), oauthTokenEndpoint, false},
+		{"delete", DefaultRetryPolicy, request(http.MethodDelete, false), killSessionEndpoint, false},
+		{"single attempt", RetryPolicy{MaxAttempts: 1}, request(http.MethodGet, false), accountStatsEndpoint, false},
+		{"zero policy", RetryPolicy{}, request(http.MethodGet, false), accountStatsEndpoint, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.allows(tt.req, tt.ep); got != tt.want {
+			t.Errorf("%v: allows = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// A body which cannot be rewound cannot be sent again.
+	req := request(http.MethodPost, true)
+	req.GetBody = nil
+	if DefaultRetryPolicy.allows(req, winsLeaderboardEndpoint) {
+		t.Error("allows request with a body which cannot be rewound")
+	}
+}
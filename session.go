@@ -1,10 +1,12 @@
 package fornitego
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
-	"net/url"
 	"strings"
 	"sync"
 	"time"
@@ -12,206 +14,443 @@ import (
 
 // Session holds connection information regarding a successful authentication with an Epic account to Epic's API. Will
 // hold a Client to use for interfacing with said API, and retain information about our authenticated session with them.
+//
+// A Session is safe for concurrent use by multiple goroutines. As the token fields are replaced upon renewal, they
+// should be read through Token while the session is in use.
 type Session struct {
 	client *Client
 
-	AccessToken  string
-	ExpiresAt    string
-	RefreshToken string
-	AccountID    string
-	ClientID     string
+	AccessToken      string
+	ExpiresAt        string
+	RefreshToken     string
+	RefreshExpiresAt string
+	AccountID        string
+	ClientID         string
 
-	username      string
-	password      string
-	launcherToken string
-	gameToken     string
+	source    TokenSource
+	store     TokenStore
+	gameToken string
+	tokens    tokenManager
+
+	renewal  *renewal
+	closed   bool
+	killed   bool
+	inflight sync.WaitGroup
 
 	mux sync.Mutex
 }
 
 // Create opens a new connection to Epic and authenticates into the game to obtain the necessary access tokens.
+//
+// Deprecated: Create terminates the program on any failure. Use NewSession instead, which returns an error.
 func Create(username, password, launcherToken, gameToken string) *Session {
-	// Initialize a new client for this session to make requests with.
-	c := newClient()
-
-	// Prepare form to request access token for launcher.
-	data := url.Values{}
-	data.Add("grant_type", "password")
-	data.Add("username", username)
-	data.Add("password", password)
-	data.Add("includePerms", "true")
-
-	// Prepare request.
-	req, err := c.NewRequest(http.MethodPost, oauthTokenURL, strings.NewReader(data.Encode()))
+	s, err := NewSession(context.Background(), username, password, launcherToken, gameToken)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
-	// Set authorization header to use launcher token.
-	req.Header.Set("Authorization", fmt.Sprintf("%v %v", AuthBasic, launcherToken))
+	return s
+}
 
-	// Process request and decode response into tokenResponse.
-	tr := &tokenResponse{}
-	resp, err := c.Do(req, tr)
-	if err != nil {
-		log.Fatalln(err)
+// NewSession opens a new connection to Epic and authenticates into the game to obtain the necessary access tokens. The
+// context provided governs every request made during authentication. Should any step of the flow fail, an *AuthError
+// is returned describing the step at fault.
+func NewSession(ctx context.Context, username, password, launcherToken, gameToken string, opts ...Option) (*Session, error) {
+	return NewSessionFromSource(ctx, PasswordTokenSource(username, password, launcherToken, gameToken), gameToken, opts...)
+}
+
+// NewSessionFromAuthorizationCode opens a new connection to Epic using an authorization code issued to the launcher
+// client, such as one obtained by logging in through a browser. This allows accounts with two-factor authentication
+// enabled to log in.
+func NewSessionFromAuthorizationCode(ctx context.Context, code, launcherToken, gameToken string, opts ...Option) (*Session, error) {
+	return NewSessionFromSource(ctx, AuthorizationCodeTokenSource(code, launcherToken, gameToken), gameToken, opts...)
+}
+
+// NewSessionFromExchangeCode opens a new connection to Epic using an exchange code, which is redeemed directly for a
+// game access token.
+func NewSessionFromExchangeCode(ctx context.Context, code, gameToken string, opts ...Option) (*Session, error) {
+	return NewSessionFromSource(ctx, ExchangeCodeTokenSource(code, gameToken), gameToken, opts...)
+}
+
+// NewSessionFromSource opens a new connection to Epic, authenticating with the token obtained from the TokenSource
+// provided. The game token is used to renew the access token once it nears expiry.
+func NewSessionFromSource(ctx context.Context, src TokenSource, gameToken string, opts ...Option) (*Session, error) {
+	// Initialize a new session, with a client for it to make requests with.
+	ret := newSession(newClient(), src, gameToken)
+	for _, opt := range opts {
+		opt(ret)
 	}
-	resp.Body.Close()
 
-	///////////////////
-	// Prepare new request for OAUTH exchange.
-	req, err = c.NewRequest(http.MethodGet, oauthExchangeURL, nil)
+	t, err := ret.login(ctx)
 	if err != nil {
-		log.Fatalln(err)
+		// Bind a pending two-factor challenge to this session so that completing it yields this session.
+		var mfa *MFARequiredError
+		if errors.As(err, &mfa) {
+			mfa.session = ret
+		}
+		return nil, err
 	}
 
-	// Set authorization header to use the access token just retrieved.
-	req.Header.Set("Authorization", fmt.Sprintf("%v %v", AuthBearer, tr.AccessToken))
+	ret.start(ctx, t)
+	return ret, nil
+}
 
-	// Process request and decode response into exchangeResponse.
-	er := &exchangeResponse{}
-	resp, err = c.Do(req, er)
-	if err != nil {
-		log.Fatalln(err)
+// newSession initializes a new, unauthenticated session.
+func newSession(c *Client, src TokenSource, gameToken string) *Session {
+	s := &Session{
+		client:    c,
+		source:    src,
+		gameToken: gameToken,
 	}
-	resp.Body.Close()
+	s.tokens.s = s
 
-	////////////////////
-	// Prepare new form for 2nd OAUTH token request for game client.
-	data = url.Values{}
-	data.Add("grant_type", "exchange_code")
-	data.Add("exchange_code", er.Code)
-	data.Add("includePerms", "true")
-	data.Add("token_type", "eg1") // should this be eg1???
+	return s
+}
 
-	req, err = c.NewRequest(http.MethodPost, oauthTokenURL, strings.NewReader(data.Encode()))
-	if err != nil {
-		log.Fatalln(err)
+// login obtains a token for the session. A token held by the session's store is resumed if it has not yet expired, or
+// renewed if its refresh token has not yet expired. Otherwise, a new token is obtained from the session's source, as it
+// is should the store fail to load.
+func (s *Session) login(ctx context.Context) (*Token, error) {
+	if s.store != nil {
+		t, err := s.store.Load(ctx)
+		if err != nil {
+			s.client.logger.Warn("token store load unsuccessful", "error", err)
+		}
+
+		if t != nil {
+			if !expiresWithin(t.ExpiresAt, time.Minute) {
+				return t, nil
+			}
+			if !expiresWithin(t.RefreshExpiresAt, time.Minute) {
+				rt, err := RefreshTokenSource(t.RefreshToken, s.gameToken).Token(ctx, s.client)
+				if err == nil {
+					return rt, nil
+				}
+			}
+		}
+	}
+
+	return s.source.Token(ctx, s.client)
+}
+
+// start assigns the token obtained upon authentication to the session and begins its background renewal.
+func (s *Session) start(ctx context.Context, t *Token) {
+	s.updateToken(ctx, t)
+
+	// Begin automatic renewal of access token in the background.
+	s.tokens.Start()
+
+	s.client.logger.Info("session created", "account_id", t.AccountID, "expires_at", t.ExpiresAt)
+}
+
+// updateToken assigns token information to the session and persists it to the session's store, if any. The token is in
+// use regardless of whether it could be persisted, so failure to store it is only logged. Returns false if the session
+// has been killed, in which case the token is discarded.
+func (s *Session) updateToken(ctx context.Context, t *Token) bool {
+	if !s.setToken(t) {
+		return false
 	}
+	s.observeExpiry(t.ExpiresAt)
 
-	// Set authorization header to use the game token.
-	req.Header.Set("Authorization", fmt.Sprintf("%v %v", AuthBasic, gameToken))
+	if s.store != nil {
+		if err := s.store.Save(ctx, t); err != nil {
+			s.client.logger.Warn("token store save unsuccessful", "account_id", t.AccountID, "error", err)
+		}
+	}
 
-	// Perform request.
-	resp, err = c.Do(req, tr)
+	return true
+}
+
+// setToken assigns token information to the session. Returns false, leaving the session untouched, if it has been
+// killed.
+func (s *Session) setToken(t *Token) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.killed {
+		return false
+	}
+
+	s.AccessToken = t.AccessToken
+	s.ExpiresAt = t.ExpiresAt
+	s.RefreshToken = t.RefreshToken
+	s.RefreshExpiresAt = t.RefreshExpiresAt
+	s.AccountID = t.AccountID
+	s.ClientID = t.ClientID
+	return true
+}
+
+// Token returns a snapshot of the session's current token information.
+func (s *Session) Token() Token {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return Token{
+		AccessToken:      s.AccessToken,
+		ExpiresAt:        s.ExpiresAt,
+		RefreshToken:     s.RefreshToken,
+		RefreshExpiresAt: s.RefreshExpiresAt,
+		AccountID:        s.AccountID,
+		ClientID:         s.ClientID,
+	}
+}
+
+// authorizedRequest prepares a new request authorized with a snapshot of the session's current access token.
+func (s *Session) authorizedRequest(ctx context.Context, ep endpoint, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := s.client.newRequest(ctx, ep, method, url, body)
 	if err != nil {
-		log.Fatalln(err)
+		return nil, err
 	}
-	resp.Body.Close()
 
-	// Create new session object from data retrieved.
-	ret := &Session{
-		client:       c,
-		AccessToken:  tr.AccessToken,
-		ExpiresAt:    tr.ExpiresAt,
-		RefreshToken: tr.RefreshToken,
-		AccountID:    tr.AccountID,
-		ClientID:     tr.ClientID,
+	// Set authorization header to use access token.
+	req.Header.Set("Authorization", fmt.Sprintf("%v %v", AuthBearer, s.Token().AccessToken))
 
-		username:      username,
-		password:      password,
-		launcherToken: launcherToken,
-		gameToken:     gameToken,
+	return req, nil
+}
+
+// maxReplays bounds the number of times a request rejected for its access token is replayed, should the token keep
+// being replaced by concurrent renewals before Epic accepts it.
+const maxReplays = 5
+
+// do performs an authorized request prepared by authorizedRequest, decoding the response into v. Should Epic reject
+// the access token, it is renewed and the request replayed with the new token. The request is replayed again if the
+// token it was replayed with has since been replaced by another renewal, up to maxReplays times.
+func (s *Session) do(req *http.Request, v interface{}) error {
+	resp, err := s.client.Do(req, v)
+
+	if unauthorized(err) && (req.Body == nil || req.GetBody != nil) {
+		stale := strings.TrimPrefix(req.Header.Get("Authorization"), AuthBearer+" ")
+		if _, rerr := s.renew(req.Context(), stale); rerr != nil {
+			if errors.Is(rerr, ErrSessionClosed) {
+				return rerr
+			}
+			return err
+		}
+
+		for i := 0; i < maxReplays; i++ {
+			// Replay the request with a fresh body and the renewed access token.
+			token := s.Token().AccessToken
+			retry := req.Clone(req.Context())
+			if req.GetBody != nil {
+				retry.Body, err = req.GetBody()
+				if err != nil {
+					return err
+				}
+			}
+			retry.Header.Set("Authorization", fmt.Sprintf("%v %v", AuthBearer, token))
+
+			resp, err = s.client.Do(retry, v)
+			if !unauthorized(err) {
+				break
+			}
+
+			// Only replay again if another renewal, possibly still in progress, has since replaced the token.
+			s.awaitRenewal(req.Context())
+			if s.Token().AccessToken == token {
+				break
+			}
+		}
+	}
+	if resp != nil {
+		resp.Body.Close()
 	}
 
-	// Spawn goroutine to handle automatic renewal of access token.
-	go ret.renewProcess()
+	return err
+}
 
-	log.Println("Session successfully created.")
-	return ret
+// unauthorized reports whether an error returned by Client.Do is Epic rejecting the access token used.
+func unauthorized(err error) bool {
+	var ae *APIError
+	return errors.As(err, &ae) && ae.StatusCode == http.StatusUnauthorized
 }
 
 // Refresh renews a session by obtaining a new access token, and replacing the hold one. Intended use it for an
 // automatic goroutine to handle scheduling of renewal. Previous token is automatically invalidated on Epic's end.
 func (s *Session) Refresh() error {
-	data := url.Values{}
-	data.Add("grant_type", "refresh_token")
-	data.Add("refresh_token", s.RefreshToken)
-	data.Add("includePerms", "true")
+	return s.RefreshContext(context.Background())
+}
 
-	// Prepare new request containing encoded body.
-	req, err := s.client.NewRequest(http.MethodPost, oauthTokenURL, strings.NewReader(data.Encode()))
-	if err != nil {
+// RefreshContext is like Refresh, but bound to the context provided.
+func (s *Session) RefreshContext(ctx context.Context) (err error) {
+	ctx, span := s.startSpan(ctx, "Refresh")
+	defer endSpan(span, &err)
+
+	if err := s.acquire(); err != nil {
 		return err
 	}
+	defer s.release()
 
-	// Use game token for authorization.
-	req.Header.Set("Authorization", fmt.Sprintf("%v %v", AuthBasic, s.gameToken))
+	_, err = s.renew(ctx, s.Token().AccessToken)
+	return err
+}
 
-	// Perform request and collect response into response object.
-	tr := &tokenResponse{}
-	resp, err := s.client.Do(req, tr)
+// observeExpiry records the time remaining until the RFC3339 expiry timestamp provided to the session's Metrics.
+func (s *Session) observeExpiry(timestamp string) {
+	if t, err := time.Parse(time.RFC3339, timestamp); err == nil {
+		s.client.metrics.SetTokenExpiry(time.Until(t))
+	}
+}
+
+// expiresWithin reports whether the RFC3339 timestamp provided falls within the given duration from now. Timestamps
+// which cannot be parsed are considered expired.
+func expiresWithin(timestamp string, d time.Duration) bool {
+	t, err := time.Parse(time.RFC3339, timestamp)
 	if err != nil {
-		log.Fatalln(err)
+		return true
 	}
-	defer resp.Body.Close()
 
-	// Assign token information to session.
-	s.mux.Lock()
-	s.AccessToken = tr.AccessToken
-	s.RefreshToken = tr.RefreshToken
-	s.ExpiresAt = tr.ExpiresAt
-	s.mux.Unlock()
+	return time.Now().Add(d).After(t)
+}
 
-	return nil
+// StartRenewal resumes automatic renewal of the session's access token in the background. Renewal is started when a
+// session is created, so this is only needed after a call to StopRenewal. Does nothing once the session is closed or
+// killed.
+func (s *Session) StartRenewal() {
+	if err := s.acquire(); err != nil {
+		return
+	}
+	defer s.release()
+
+	if s.terminated() {
+		return
+	}
+
+	s.tokens.Start()
+}
+
+// StopRenewal halts automatic renewal of the session's access token, waiting for any renewal in progress to finish.
+func (s *Session) StopRenewal() {
+	s.tokens.Stop(context.Background())
 }
 
 // Kill terminates an existing session by sending a DELETE request to deactivate the session on Epic's servers.
+// Automatic renewal of the access token is stopped, and requests made with the session afterwards return
+// ErrSessionClosed.
 func (s *Session) Kill() error {
+	return s.KillContext(context.Background())
+}
+
+// KillContext is like Kill, but bound to the context provided. A session which Close failed to terminate may still be
+// killed.
+func (s *Session) KillContext(ctx context.Context) (err error) {
+	ctx, span := s.startSpan(ctx, "Kill")
+	defer endSpan(span, &err)
+
+	if err := s.acquire(); err != nil {
+		if s.terminated() {
+			return err
+		}
+		return s.kill(ctx)
+	}
+	defer s.release()
+
+	return s.kill(ctx)
+}
+
+// Close stops automatic renewal of the access token, waits for any requests in progress to finish, and terminates the
+// session on Epic's servers. Any use of the session afterwards returns ErrSessionClosed. Should the context be done
+// before then, or termination fail, the error is returned and the session is left closed without being terminated, in
+// which case Close may be called again to retry.
+func (s *Session) Close(ctx context.Context) (err error) {
+	ctx, span := s.startSpan(ctx, "Close")
+	defer endSpan(span, &err)
+
 	s.mux.Lock()
-	defer s.mux.Unlock()
+	killed := s.killed
+	s.closed = true
+	s.mux.Unlock()
+
+	if killed {
+		return ErrSessionClosed
+	}
+
+	if err := s.tokens.Stop(ctx); err != nil {
+		return err
+	}
+
+	// Wait for requests in progress, giving up once the context is done.
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return s.kill(ctx)
+}
+
+// kill stops automatic renewal and deactivates the session's access token on Epic's servers. The token held by the
+// session's store, if any, is invalidated so that it is not resumed.
+func (s *Session) kill(ctx context.Context) error {
+	if err := s.tokens.Stop(ctx); err != nil {
+		return err
+	}
+
+	accessToken := s.Token().AccessToken
 
-	req, err := s.client.NewRequest(http.MethodDelete, killSessionURL+"/"+s.AccessToken, nil)
+	u := s.client.endpoints.Account + killSessionPath + "/" + accessToken
+	req, err := s.client.newRequest(ctx, killSessionEndpoint, http.MethodDelete, u, nil)
 	if err != nil {
 		return err
 	}
 
 	// Set authentication header to use access token.
-	req.Header.Set("Authorization", fmt.Sprintf("%v %v", AuthBearer, s.AccessToken))
+	req.Header.Set("Authorization", fmt.Sprintf("%v %v", AuthBearer, accessToken))
 
-	_, err = s.client.Do(req, nil)
+	resp, err := s.client.Do(req, nil)
 	if err != nil {
 		return err
 	}
+	resp.Body.Close()
 
 	// Clear session information.
+	s.mux.Lock()
 	s.AccessToken = ""
 	s.ExpiresAt = ""
 	s.RefreshToken = ""
+	s.RefreshExpiresAt = ""
+	s.killed = true
+	s.mux.Unlock()
+
+	// Replace the stored token with the cleared one, which having no expiry will never be resumed.
+	t := s.Token()
+	if s.store != nil {
+		if err := s.store.Save(ctx, &t); err != nil {
+			s.client.logger.Warn("token store save unsuccessful", "account_id", t.AccountID, "error", err)
+		}
+	}
 
-	log.Println("Session token successfully deactivated.")
+	s.client.logger.Info("session token deactivated", "account_id", t.AccountID)
 	return nil
 }
 
-// renewProcess is a goroutine intended to be running during the lifetime of a Session. Its intention is to handle
-// automatic renewal of access token within a necessary time for renewal to ensure the API stays connected and
-// functional.
-func (s *Session) renewProcess() {
-	// Check every 20 seconds if we need to update access token.
-	updateChecker := time.NewTimer(time.Second * 20)
+// terminated reports whether the session's access token has been deactivated by kill.
+func (s *Session) terminated() bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
 
-	// Locks until timer above has passed.
-	<-updateChecker.C
+	return s.killed
+}
 
-	// Parse expiration time into Time object.
-	expiresAt, err := time.Parse(time.RFC3339, s.ExpiresAt)
-	if err != nil {
-		log.Println(err)
-		return
-	}
+// acquire registers a request in progress on the session, so that Close may wait for it to finish. Returns
+// ErrSessionClosed if the session has been closed. Every successful call must be paired with a call to release.
+func (s *Session) acquire() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
 
-	// If the token expiration time does not expire within the next minute, wait and try again.
-	if !time.Now().After(expiresAt.Add(-time.Minute - 1)) {
-		defer s.renewProcess()
-		return
+	if s.closed {
+		return ErrSessionClosed
 	}
 
-	// Token expiry is imminent, renew.
-	err = s.Refresh()
-	if err != nil {
-		log.Println("Token renewal unsuccessful: " + err.Error())
-	}
+	s.inflight.Add(1)
+	return nil
+}
 
-	log.Println("Token renewed successfully.")
-	defer s.renewProcess()
+// release marks a request registered with acquire as finished.
+func (s *Session) release() {
+	s.inflight.Done()
 }
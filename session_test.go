@@ -0,0 +1,252 @@
+package fornitego
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEpic is a local stand-in for Epic's services. It issues a new access token for every token request, and rejects
+// requests authorized with any token but the latest.
+type fakeEpic struct {
+	*httptest.Server
+
+	mux       sync.Mutex
+	token     string
+	issued    int
+	refreshed int
+
+	// If set, account lookups signal their arrival and are held until released.
+	arrived chan struct{}
+	release chan struct{}
+}
+
+func newFakeEpic(t *testing.T) *fakeEpic {
+	f := &fakeEpic{}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+// session logs in to the fake server, closing the session once the test is finished.
+func (f *fakeEpic) session(t *testing.T, opts ...Option) *Session {
+	opts = append([]Option{WithEndpoints(Endpoints{f.URL, f.URL, f.URL, f.URL})}, opts...)
+	s, err := NewSession(context.Background(), "user", "pass", "launcher", "game", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close(context.Background()) })
+
+	return s
+}
+
+// revoke invalidates the current access token, as if it had expired on Epic's end.
+func (f *fakeEpic) revoke() {
+	f.mux.Lock()
+	f.token = "revoked"
+	f.mux.Unlock()
+}
+
+func (f *fakeEpic) refreshes() int {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	return f.refreshed
+}
+
+func (f *fakeEpic) serve(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case oauthTokenPath:
+		r.ParseForm()
+
+		f.mux.Lock()
+		f.issued++
+		if r.Form.Get("grant_type") == "refresh_token" {
+			f.refreshed++
+		}
+		f.token = fmt.Sprintf("token-%v", f.issued)
+		tr := tokenResponse{
+			AccessToken:      f.token,
+			ExpiresAt:        time.Now().Add(time.Hour).Format(time.RFC3339),
+			RefreshToken:     "refresh-" + f.token,
+			RefreshExpiresAt: time.Now().Add(8 * time.Hour).Format(time.RFC3339),
+			AccountID:        "account",
+		}
+		f.mux.Unlock()
+
+		json.NewEncoder(w).Encode(tr)
+		return
+	}
+
+	f.mux.Lock()
+	authorized := r.Header.Get("Authorization") == AuthBearer+" "+f.token
+	arrived, release := f.arrived, f.release
+	f.mux.Unlock()
+	if !authorized {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, `{"errorCode":%q}`, errorCodeInvalidToken)
+		return
+	}
+
+	switch {
+	case r.URL.Path == oauthExchangePath:
+		fmt.Fprint(w, `{"code":"exchange"}`)
+	case strings.HasPrefix(r.URL.Path, killSessionPath):
+		w.WriteHeader(http.StatusNoContent)
+	case r.URL.Path == accountLookupPath+"/lookup":
+		if arrived != nil {
+			arrived <- struct{}{}
+			<-release
+		}
+		fmt.Fprint(w, `{"id":"player","displayName":"Player"}`)
+	case r.URL.Path == accountInfoPath:
+		fmt.Fprint(w, `[{"id":"player","displayName":"Player"}]`)
+	case strings.HasPrefix(r.URL.Path, accountStatsPath):
+		fmt.Fprint(w, `[{"name":"br_kills_pc_m0_p2","value":10},{"name":"br_matchesplayed_pc_m0_p2","value":5}]`)
+	case strings.HasPrefix(r.URL.Path, "/fortnite/api/leaderboards/"):
+		fmt.Fprint(w, `{"entries":[{"accountId":"player","value":3,"rank":1}]}`)
+	case strings.HasPrefix(r.URL.Path, "/lightswitch/"):
+		fmt.Fprint(w, `[{"status":"UP"}]`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSessionConcurrentUse(t *testing.T) {
+	f := newFakeEpic(t)
+	s := f.session(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			p, err := s.QueryPlayer("Player", "", PC)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if p.Stats.Solo.Kills != 10 {
+				t.Errorf("got %v solo kills, want 10", p.Stats.Solo.Kills)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if ok, err := s.CheckStatus(); !ok || err != nil {
+				t.Errorf("got status %v, %v", ok, err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			lb, err := s.GetWinsLeaderboard(PC, Solo)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(*lb) != 1 || (*lb)[0].DisplayName != "Player" {
+				t.Errorf("got leaderboard %+v", *lb)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := s.Refresh(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := s.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CheckStatus(); !errors.Is(err, ErrSessionClosed) {
+		t.Fatalf("got %v after close, want ErrSessionClosed", err)
+	}
+}
+
+func TestSessionRenewsOnceOnUnauthorized(t *testing.T) {
+	f := newFakeEpic(t)
+	s := f.session(t)
+	f.revoke()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.CheckStatus(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := f.refreshes(); n != 1 {
+		t.Fatalf("got %v refreshes, want 1", n)
+	}
+}
+
+func TestSessionCloseWaitsForRequests(t *testing.T) {
+	f := newFakeEpic(t)
+	f.mux.Lock()
+	f.arrived = make(chan struct{})
+	f.release = make(chan struct{})
+	f.mux.Unlock()
+	s := f.session(t)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := s.QueryPlayer("Player", "", PC)
+		errs <- err
+	}()
+	<-f.arrived
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- s.Close(context.Background())
+	}()
+
+	// Hold the query in progress until Close has begun refusing new requests.
+	for {
+		if _, err := s.CheckStatus(); errors.Is(err, ErrSessionClosed) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(f.release)
+
+	if err := <-errs; err != nil {
+		t.Fatalf("query in progress during close: %v", err)
+	}
+	if err := <-closed; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSessionKilledDoesNotRenew(t *testing.T) {
+	f := newFakeEpic(t)
+	s := f.session(t)
+
+	if err := s.Kill(); err != nil {
+		t.Fatal(err)
+	}
+	s.StartRenewal()
+
+	if _, err := s.CheckStatus(); !errors.Is(err, ErrSessionClosed) {
+		t.Fatalf("got %v after kill, want ErrSessionClosed", err)
+	}
+	if err := s.Refresh(); !errors.Is(err, ErrSessionClosed) {
+		t.Fatalf("got %v refreshing after kill, want ErrSessionClosed", err)
+	}
+	if tok := s.Token().AccessToken; tok != "" {
+		t.Fatalf("got access token %q after kill", tok)
+	}
+}
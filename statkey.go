@@ -0,0 +1,83 @@
+package fornitego
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StatKey is the structured form of the name Epic records a statistic under, such as "br_kills_pc_m0_p2".
+type StatKey struct {
+	Stat     string // The statistic recorded, such as "kills" or "placetop1".
+	Platform string // The platform played on, such as PC.
+	Playlist string // The playlist played, such as "p2" for solo.
+}
+
+// ParseStatKey parses the name of a statistic recorded by Epic, in the form br_<stat>_<platform>_m0_<playlist>.
+// Returns an error wrapping ErrInvalidStatKey should the name not be in that form.
+func ParseStatKey(name string) (StatKey, error) {
+	rest := strings.TrimPrefix(name, "br_")
+	if rest == name {
+		return StatKey{}, fmt.Errorf("%w: %q", ErrInvalidStatKey, name)
+	}
+
+	// Split the playlist off at the mode separator, as playlists may themselves contain underscores.
+	i := strings.Index(rest, "_m0_")
+	if i < 0 {
+		return StatKey{}, fmt.Errorf("%w: %q", ErrInvalidStatKey, name)
+	}
+	head, playlist := rest[:i], rest[i+len("_m0_"):]
+
+	// The platform is the last segment before the separator, and everything prior the statistic.
+	j := strings.LastIndex(head, "_")
+	if j <= 0 || j == len(head)-1 || playlist == "" {
+		return StatKey{}, fmt.Errorf("%w: %q", ErrInvalidStatKey, name)
+	}
+
+	return StatKey{
+		Stat:     head[:j],
+		Platform: head[j+1:],
+		Playlist: playlist,
+	}, nil
+}
+
+// String returns the key in the form Epic records it under.
+func (k StatKey) String() string {
+	return fmt.Sprintf("br_%v_%v_m0_%v", k.Stat, k.Platform, k.Playlist)
+}
+
+// setStat assigns the value of a statistic to the field of the StatDetails it corresponds to. Returns false if the
+// statistic is not one held by StatDetails.
+func setStat(d *StatDetails, stat string, value int) bool {
+	switch stat {
+	case "placetop1":
+		d.Wins = value
+	case "placetop3":
+		d.Top3 = value
+	case "placetop5":
+		d.Top5 = value
+	case "placetop6":
+		d.Top6 = value
+	case "placetop10":
+		d.Top10 = value
+	case "placetop12":
+		d.Top12 = value
+	case "placetop25":
+		d.Top25 = value
+	case "matchesplayed":
+		d.Matches = value
+	case "kills":
+		d.Kills = value
+	case "score":
+		d.Score = value
+	case "minutesplayed":
+		d.MinutesPlayed = value
+	case "lastmodified":
+		t := time.Unix(int64(value), 0).UTC()
+		d.LastModified = &t
+	default:
+		return false
+	}
+
+	return true
+}
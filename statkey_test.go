@@ -0,0 +1,73 @@
+package fornitego
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseStatKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want StatKey
+		err  bool
+	}{
+		{name: "br_kills_pc_m0_p2", want: StatKey{Stat: "kills", Platform: "pc", Playlist: "p2"}},
+		{name: "br_placetop10_xb1_m0_p10", want: StatKey{Stat: "placetop10", Platform: "xb1", Playlist: "p10"}},
+		{name: "br_kills_ps4_m0_playlist_defaultsolo",
+			want: StatKey{Stat: "kills", Platform: "ps4", Playlist: "playlist_defaultsolo"}},
+		{name: "br_minutes_played_pc_m0_p9", want: StatKey{Stat: "minutes_played", Platform: "pc", Playlist: "p9"}},
+		{name: "br_kills_m0_p2", err: true},  // Missing platform.
+		{name: "br_kills__m0_p2", err: true}, // Empty platform.
+		{name: "br_kills_pc_m0_", err: true}, // Empty playlist.
+		{name: "br_kills_pc_p2", err: true},  // Missing mode separator.
+		{name: "kills_pc_m0_p2", err: true},  // Missing br_ prefix.
+		{name: "br__pc_m0_p2", err: true},    // Empty stat.
+		{name: "", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseStatKey(tt.name)
+		if tt.err {
+			if !errors.Is(err, ErrInvalidStatKey) {
+				t.Errorf("ParseStatKey(%q) = %+v, %v, want ErrInvalidStatKey", tt.name, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseStatKey(%q) = %+v, %v, want %+v", tt.name, got, err, tt.want)
+		}
+		if got.String() != tt.name {
+			t.Errorf("ParseStatKey(%q).String() = %q", tt.name, got.String())
+		}
+	}
+}
+
+func TestMapStatsUnknownKeys(t *testing.T) {
+	var sr StatsResponse
+	err := json.Unmarshal([]byte(`[
+		{"name": "br_kills_pc_m0_p9", "value": 4},
+		{"name": "br_kills_pc_m0_p99", "value": 7},
+		{"name": "br_assists_pc_m0_p9", "value": 3},
+		{"name": "br_kills_ps4_m0_p9", "value": 1},
+		{"name": "malformed", "value": 2}
+	]`), &sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st := (&Session{}).mapStats(&sr, PC)
+	if st.Squad.Kills != 4 {
+		t.Errorf("got %v squad kills, want 4", st.Squad.Kills)
+	}
+
+	want := map[string]int{"br_assists_pc_m0_p9": 3, "malformed": 2}
+	if len(st.Unmapped) != len(want) {
+		t.Errorf("got unmapped %v, want %v", st.Unmapped, want)
+	}
+	for k, v := range want {
+		if st.Unmapped[k] != v {
+			t.Errorf("got unmapped %v, want %v", st.Unmapped, want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package fornitego
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// TokenStore persists the tokens of a Session so that they may be reused across restarts rather than performing a
+// full login every time.
+type TokenStore interface {
+	// Load returns the stored token, or nil if no token has been stored.
+	Load(ctx context.Context) (*Token, error)
+	// Save stores the token provided, replacing any token previously stored.
+	Save(ctx context.Context, t *Token) error
+}
+
+// MemoryTokenStore is a TokenStore which holds a token in memory. Useful for sharing tokens between sessions within a
+// single process.
+type MemoryTokenStore struct {
+	t   *Token
+	mux sync.Mutex
+}
+
+// NewMemoryTokenStore returns a new, empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{}
+}
+
+// Load returns a copy of the token held in memory.
+func (m *MemoryTokenStore) Load(ctx context.Context) (*Token, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if m.t == nil {
+		return nil, nil
+	}
+
+	t := *m.t
+	return &t, nil
+}
+
+// Save holds a copy of the token provided in memory.
+func (m *MemoryTokenStore) Save(ctx context.Context, t *Token) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	c := *t
+	m.t = &c
+	return nil
+}
+
+// FileTokenStore is a TokenStore which writes a token to a JSON file readable only by the current user. If a key is
+// provided, the file contents are encrypted with AES-GCM using a key derived from it.
+type FileTokenStore struct {
+	path string
+	key  []byte
+	mux  sync.Mutex
+}
+
+// NewFileTokenStore returns a FileTokenStore writing to the file at path. The key may be nil to store the token
+// unencrypted.
+func NewFileTokenStore(path string, key []byte) *FileTokenStore {
+	f := &FileTokenStore{path: path}
+	if key != nil {
+		k := sha256.Sum256(key)
+		f.key = k[:]
+	}
+
+	return f
+}
+
+// Load reads the token from the file. Returns nil if the file does not exist.
+func (f *FileTokenStore) Load(ctx context.Context) (*Token, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	b, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if f.key != nil {
+		b, err = f.decrypt(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	t := &Token{}
+	if err := json.Unmarshal(b, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Save writes the token to the file, replacing its contents.
+func (f *FileTokenStore) Save(ctx context.Context, t *Token) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	if f.key != nil {
+		b, err = f.encrypt(b)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeFile(f.path, b)
+}
+
+// writeFile atomically replaces the file at path with the data provided, readable only by the current user. The data
+// is written to a temporary file alongside it, which is renamed into place once complete.
+func writeFile(path string, b []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// encrypt seals the plaintext provided, prefixing the result with the nonce used.
+func (f *FileTokenStore) encrypt(plaintext []byte) ([]byte, error) {
+	gcm, err := f.cipher()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// decrypt opens ciphertext previously sealed by encrypt.
+func (f *FileTokenStore) decrypt(ciphertext []byte) ([]byte, error) {
+	gcm, err := f.cipher()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("token store file is malformed")
+	}
+
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
+
+// cipher prepares the AES-GCM cipher used to encrypt the file contents.
+func (f *FileTokenStore) cipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(f.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,255 @@
+package fornitego
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Token holds the credentials issued by Epic upon a successful authentication.
+type Token struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresAt        string `json:"expires_at"`
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresAt string `json:"refresh_expires_at"`
+	AccountID        string `json:"account_id"`
+	ClientID         string `json:"client_id"`
+}
+
+// TokenSource is anything capable of obtaining a Token from Epic's API. Sources should use the Client provided to
+// perform any requests, so that they are bound to the same configuration as the Session they authenticate.
+type TokenSource interface {
+	Token(ctx context.Context, c *Client) (*Token, error)
+}
+
+// PasswordTokenSource returns a TokenSource performing the username and password login flow. The launcher token is
+// used to obtain a launcher access token, which is then exchanged for a game access token using the game token.
+func PasswordTokenSource(username, password, launcherToken, gameToken string) TokenSource {
+	return &passwordSource{
+		username:      username,
+		password:      password,
+		launcherToken: launcherToken,
+		gameToken:     gameToken,
+	}
+}
+
+type passwordSource struct {
+	username      string
+	password      string
+	launcherToken string
+	gameToken     string
+}
+
+func (p *passwordSource) Token(ctx context.Context, c *Client) (*Token, error) {
+	// Prepare form to request access token for launcher.
+	data := url.Values{}
+	data.Add("grant_type", "password")
+	data.Add("username", p.username)
+	data.Add("password", p.password)
+	data.Add("includePerms", "true")
+
+	tr, err := requestToken(ctx, c, p.launcherToken, data)
+	if err != nil {
+		// Accounts with two-factor authentication enabled are challenged for a one-time code instead.
+		var ae *APIError
+		if errors.As(err, &ae) && ae.ErrorCode == errorCodeMFARequired {
+			mc := &mfaChallenge{}
+			if err := json.Unmarshal(ae.Body, mc); err != nil {
+				return nil, &AuthError{Step: AuthStepPassword, Err: err}
+			}
+
+			return nil, &MFARequiredError{
+				Challenge:        mc.Challenge,
+				Method:           mc.Metadata.TwoFactorMethod,
+				AlternateMethods: mc.Metadata.AlternateMethods,
+				Message:          ae.ErrorMessage,
+				session:          newSession(c, p, p.gameToken),
+				launcherToken:    p.launcherToken,
+			}
+		}
+		return nil, &AuthError{Step: AuthStepPassword, Err: err}
+	}
+
+	return exchangeForGame(ctx, c, tr.AccessToken, p.gameToken)
+}
+
+// completeTwoFactor submits a one-time code in response to a two-factor authentication challenge issued during the
+// password flow, then exchanges the resulting launcher access token for a game access token.
+func completeTwoFactor(ctx context.Context, c *Client, challenge, code, launcherToken, gameToken string) (*Token, error) {
+	data := url.Values{}
+	data.Add("grant_type", "otp")
+	data.Add("otp", code)
+	data.Add("challenge", challenge)
+	data.Add("includePerms", "true")
+
+	tr, err := requestToken(ctx, c, launcherToken, data)
+	if err != nil {
+		return nil, &AuthError{Step: AuthStepTwoFactor, Err: err}
+	}
+
+	return exchangeForGame(ctx, c, tr.AccessToken, gameToken)
+}
+
+// AuthorizationCodeTokenSource returns a TokenSource which redeems an authorization code issued to the launcher client,
+// such as one obtained by logging in through a browser, and exchanges the resulting launcher access token for a game
+// access token. Authorization codes may only be used once.
+func AuthorizationCodeTokenSource(code, launcherToken, gameToken string) TokenSource {
+	return &authorizationCodeSource{code: code, launcherToken: launcherToken, gameToken: gameToken}
+}
+
+type authorizationCodeSource struct {
+	code          string
+	launcherToken string
+	gameToken     string
+}
+
+func (a *authorizationCodeSource) Token(ctx context.Context, c *Client) (*Token, error) {
+	data := url.Values{}
+	data.Add("grant_type", "authorization_code")
+	data.Add("code", a.code)
+	data.Add("includePerms", "true")
+
+	tr, err := requestToken(ctx, c, a.launcherToken, data)
+	if err != nil {
+		return nil, &AuthError{Step: AuthStepAuthorizationCode, Err: err}
+	}
+
+	return exchangeForGame(ctx, c, tr.AccessToken, a.gameToken)
+}
+
+// exchangeForGame exchanges a launcher access token for one belonging to the game client.
+func exchangeForGame(ctx context.Context, c *Client, launcherAccessToken, gameToken string) (*Token, error) {
+	code, err := requestExchangeCode(ctx, c, launcherAccessToken)
+	if err != nil {
+		return nil, &AuthError{Step: AuthStepExchange, Err: err}
+	}
+
+	return ExchangeCodeTokenSource(code, gameToken).Token(ctx, c)
+}
+
+// ExchangeCodeTokenSource returns a TokenSource which redeems an exchange code for a game access token. Exchange codes
+// may only be used once, so the source cannot be used to authenticate again once successful.
+func ExchangeCodeTokenSource(code, gameToken string) TokenSource {
+	return &exchangeCodeSource{code: code, gameToken: gameToken}
+}
+
+type exchangeCodeSource struct {
+	code      string
+	gameToken string
+}
+
+func (e *exchangeCodeSource) Token(ctx context.Context, c *Client) (*Token, error) {
+	// Prepare new form for OAUTH token request for game client.
+	data := url.Values{}
+	data.Add("grant_type", "exchange_code")
+	data.Add("exchange_code", e.code)
+	data.Add("includePerms", "true")
+	data.Add("token_type", "eg1") // should this be eg1???
+
+	tr, err := requestToken(ctx, c, e.gameToken, data)
+	if err != nil {
+		return nil, &AuthError{Step: AuthStepExchangeCode, Err: err}
+	}
+
+	return tr.token(), nil
+}
+
+// RefreshTokenSource returns a TokenSource which obtains a new game access token using a previously issued refresh
+// token.
+func RefreshTokenSource(refreshToken, gameToken string) TokenSource {
+	return &refreshSource{refreshToken: refreshToken, gameToken: gameToken}
+}
+
+type refreshSource struct {
+	refreshToken string
+	gameToken    string
+}
+
+func (r *refreshSource) Token(ctx context.Context, c *Client) (*Token, error) {
+	data := url.Values{}
+	data.Add("grant_type", "refresh_token")
+	data.Add("refresh_token", r.refreshToken)
+	data.Add("includePerms", "true")
+
+	tr, err := requestToken(ctx, c, r.gameToken, data)
+	if err != nil {
+		return nil, &AuthError{Step: AuthStepRefresh, Err: err}
+	}
+
+	return tr.token(), nil
+}
+
+// StaticTokenSource returns a TokenSource which always returns the same pre-obtained token. No requests are made.
+func StaticTokenSource(t *Token) TokenSource {
+	return staticSource{t: t}
+}
+
+type staticSource struct {
+	t *Token
+}
+
+func (s staticSource) Token(ctx context.Context, c *Client) (*Token, error) {
+	t := *s.t
+	return &t, nil
+}
+
+// requestToken performs a request to the OAUTH token endpoint with the form provided, authorizing with the basic
+// client token given.
+func requestToken(ctx context.Context, c *Client, clientToken string, data url.Values) (*tokenResponse, error) {
+	u := c.endpoints.Account + oauthTokenPath
+	req, err := c.newRequest(ctx, oauthTokenEndpoint, http.MethodPost, u, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	// Set authorization header to use the client token.
+	req.Header.Set("Authorization", fmt.Sprintf("%v %v", AuthBasic, clientToken))
+
+	// Process request and decode response into tokenResponse.
+	tr := &tokenResponse{}
+	resp, err := c.Do(req, tr)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return tr, nil
+}
+
+// requestExchangeCode obtains a single-use exchange code for the account owning the access token provided.
+func requestExchangeCode(ctx context.Context, c *Client, accessToken string) (string, error) {
+	u := c.endpoints.Account + oauthExchangePath
+	req, err := c.newRequest(ctx, oauthExchangeEndpoint, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+
+	// Set authorization header to use the access token.
+	req.Header.Set("Authorization", fmt.Sprintf("%v %v", AuthBearer, accessToken))
+
+	// Process request and decode response into exchangeResponse.
+	er := &exchangeResponse{}
+	resp, err := c.Do(req, er)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	return er.Code, nil
+}
+
+// token converts the response from the OAUTH token endpoint into a Token.
+func (tr *tokenResponse) token() *Token {
+	return &Token{
+		AccessToken:      tr.AccessToken,
+		ExpiresAt:        tr.ExpiresAt,
+		RefreshToken:     tr.RefreshToken,
+		RefreshExpiresAt: tr.RefreshExpiresAt,
+		AccountID:        tr.AccountID,
+		ClientID:         tr.ClientID,
+	}
+}
@@ -0,0 +1,64 @@
+package fornitego
+
+import (
+	"context"
+	"net/http"
+)
+
+// Tracer creates spans tracing the operations of a Session. Every public Session method is traced with a span, with a
+// child span for each HTTP request it makes. An OpenTelemetry tracer is adapted by wrapping its trace.Tracer for Start
+// and its propagation.TextMapPropagator for Inject, using propagation.HeaderCarrier.
+type Tracer interface {
+	// Start begins a span as a child of the span held by the context, if any, returning a context holding the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+	// Inject writes the trace context held by the context into the headers of an outgoing request, such as the W3C
+	// traceparent and tracestate headers.
+	Inject(ctx context.Context, h http.Header)
+}
+
+// Span is a single operation traced by a Tracer.
+type Span interface {
+	// SetAttribute attaches a key and value describing the operation to the span.
+	SetAttribute(key string, value interface{})
+	// RecordError marks the operation as having failed with the error provided.
+	RecordError(err error)
+	// End completes the span.
+	End()
+}
+
+// WithTracer sets the Tracer used to trace the operations of a Session. By default, nothing is traced.
+func WithTracer(t Tracer) Option {
+	return func(s *Session) {
+		s.client.tracer = t
+	}
+}
+
+// nopTracer is a Tracer which records nothing.
+type nopTracer struct{}
+
+func (nopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+func (nopTracer) Inject(ctx context.Context, h http.Header) {}
+
+// nopSpan is the Span returned by nopTracer.
+type nopSpan struct{}
+
+func (nopSpan) SetAttribute(key string, value interface{}) {}
+func (nopSpan) RecordError(err error)                      {}
+func (nopSpan) End()                                       {}
+
+// startSpan begins a span for a public Session method, named after it.
+func (s *Session) startSpan(ctx context.Context, method string) (context.Context, Span) {
+	return s.client.tracer.Start(ctx, "fortnite."+method)
+}
+
+// endSpan records the error pointed to, if any, and completes the span. Intended to be deferred with a pointer to a
+// named error result. Credentials in the URL of a failed request are redacted from the error recorded.
+func endSpan(span Span, err *error) {
+	if *err != nil {
+		span.RecordError(redactError(*err))
+	}
+	span.End()
+}